@@ -1,7 +1,10 @@
 package apps
 
 type Apps struct {
-	AppTeamMappings string
-	DefaultTeam     string
-	RetiredTeams    string
+	AppTeamMappings     string
+	DefaultTeam         string
+	KnownTeams          string
+	RetiredTeams        string
+	TeamMappingsFile    string
+	UnknownTeamFallback string
 }
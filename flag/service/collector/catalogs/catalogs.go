@@ -0,0 +1,7 @@
+package catalogs
+
+type Catalogs struct {
+	Allowlist     string
+	Denylist      string
+	LabelSelector string
+}
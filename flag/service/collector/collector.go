@@ -2,10 +2,19 @@ package collector
 
 import (
 	"github.com/giantswarm/app-exporter/flag/service/collector/apps"
+	"github.com/giantswarm/app-exporter/flag/service/collector/catalogs"
+	"github.com/giantswarm/app-exporter/flag/service/collector/configreferences"
+	"github.com/giantswarm/app-exporter/flag/service/collector/events"
+	"github.com/giantswarm/app-exporter/flag/service/collector/kubeconfigsecrets"
 	"github.com/giantswarm/app-exporter/flag/service/collector/provider"
 )
 
 type Collector struct {
-	Apps     apps.Apps
-	Provider provider.Provider
+	Apps              apps.Apps
+	Catalogs          catalogs.Catalogs
+	ConfigReferences  configreferences.ConfigReferences
+	Events            events.Events
+	KubeConfigSecrets kubeconfigsecrets.KubeConfigSecrets
+	Provider          provider.Provider
+	Timeout           string
 }
@@ -0,0 +1,5 @@
+package configreferences
+
+type ConfigReferences struct {
+	Enabled string
+}
@@ -0,0 +1,5 @@
+package kubeconfigsecrets
+
+type KubeConfigSecrets struct {
+	Enabled string
+}
@@ -15,7 +15,8 @@ type TLS struct {
 // Watch is a data structure to hold Kubernetes specific configuration
 // for watching for Kubernetes resources.
 type Watch struct {
-	Namespace string
+	LabelSelector string
+	Namespace     string
 }
 
 // Kubernetes is a data structure to hold Kubernetes specific command line
@@ -29,8 +30,15 @@ type Kubernetes struct {
 	Watch          Watch
 }
 
+// Replay is a data structure to hold the configuration for serving metrics
+// from YAML manifests instead of a live cluster.
+type Replay struct {
+	Dir string
+}
+
 // Service is an intermediate data structure for command line configuration flags.
 type Service struct {
 	Collector  collector.Collector
 	Kubernetes Kubernetes
+	Replay     Replay
 }
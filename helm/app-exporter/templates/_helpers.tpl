@@ -45,3 +45,59 @@ Define image tag.
 {{- .Chart.AppVersion }}
 {{- end }}
 {{- end }}
+
+{{/*
+RBAC rules for the resources read by the collectors.
+*/}}
+{{- define "rbac.rules.resources" -}}
+- apiGroups:
+    - application.giantswarm.io
+  resources:
+    - apps
+    - appcatalogentries
+    - catalogs
+  verbs:
+    - get
+    - list
+    - watch
+- apiGroups:
+    - apps
+  resources:
+    - deployments
+  verbs:
+    - list
+    - watch
+{{- if .Values.config.configReferences.enabled }}
+# Kubernetes cannot restrict access to metadata, so this grants reading the
+# full content of every configmap and secret in the namespace. The exporter
+# only reads and caches their metadata though.
+- apiGroups:
+    - ""
+  resources:
+    - configmaps
+    - secrets
+  verbs:
+    - list
+    - watch
+{{- else if .Values.config.kubeConfigSecrets.enabled }}
+# Kubernetes cannot restrict access to metadata, so this grants reading the
+# full content of every secret. The exporter only reads and caches their
+# metadata though.
+- apiGroups:
+    - ""
+  resources:
+    - secrets
+  verbs:
+    - list
+    - watch
+{{- end }}
+{{- if .Values.config.events.enabled }}
+- apiGroups:
+    - ""
+  resources:
+    - events
+  verbs:
+    - create
+    - patch
+{{- end }}
+{{- end -}}
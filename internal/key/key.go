@@ -1,6 +1,122 @@
 package key
 
-import "strings"
+import (
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+)
+
+const (
+	KindConfigMap = "ConfigMap"
+	KindSecret    = "Secret"
+)
+
+// AnnotationDependsOn lists the apps, comma separated, which app-operator
+// installs before the annotated App CR. It is not part of k8smetadata.
+const AnnotationDependsOn = "app-operator.giantswarm.io/depends-on"
+
+// ConfigReference is a ConfigMap or Secret an App CR references.
+type ConfigReference struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// ConfigReferences returns the ConfigMaps and Secrets referenced by the App CR
+// in spec.config, spec.userConfig and spec.extraConfigs. References without
+// name or namespace are skipped. Extra configs default to ConfigMaps like in
+// app-operator.
+func ConfigReferences(app v1alpha1.App) []ConfigReference {
+	candidates := []ConfigReference{
+		{Kind: KindConfigMap, Name: app.Spec.Config.ConfigMap.Name, Namespace: app.Spec.Config.ConfigMap.Namespace},
+		{Kind: KindSecret, Name: app.Spec.Config.Secret.Name, Namespace: app.Spec.Config.Secret.Namespace},
+		{Kind: KindConfigMap, Name: app.Spec.UserConfig.ConfigMap.Name, Namespace: app.Spec.UserConfig.ConfigMap.Namespace},
+		{Kind: KindSecret, Name: app.Spec.UserConfig.Secret.Name, Namespace: app.Spec.UserConfig.Secret.Namespace},
+	}
+
+	for _, c := range app.Spec.ExtraConfigs {
+		kind := KindConfigMap
+		if c.Kind == "secret" {
+			kind = KindSecret
+		}
+
+		candidates = append(candidates, ConfigReference{Kind: kind, Name: c.Name, Namespace: c.Namespace})
+	}
+
+	var refs []ConfigReference
+	for _, ref := range candidates {
+		if ref.Name == "" || ref.Namespace == "" || slices.Contains(refs, ref) {
+			continue
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// KubeConfigReference returns the Secret holding the kubeconfig of the
+// workload cluster the App CR is installed to. It returns false for App CRs
+// installed in-cluster. The reference may be incomplete when the App CR is
+// misconfigured.
+func KubeConfigReference(app v1alpha1.App) (ConfigReference, bool) {
+	if app.Spec.KubeConfig.InCluster {
+		return ConfigReference{}, false
+	}
+
+	ref := ConfigReference{
+		Kind:      KindSecret,
+		Name:      app.Spec.KubeConfig.Secret.Name,
+		Namespace: app.Spec.KubeConfig.Secret.Namespace,
+	}
+
+	return ref, true
+}
+
+// CordonReason returns the cordon-reason annotation of the App CR. The
+// app-operator annotation checked by key.IsAppCordoned is preferred. The
+// chart-operator annotation returned by key.CordonReason is the fallback.
+func CordonReason(app v1alpha1.App) string {
+	if v, ok := app.Annotations[annotation.AppOperatorCordonReason]; ok {
+		return v
+	}
+
+	return app.Annotations[annotation.ChartOperatorCordonReason]
+}
+
+// CordonUntil returns the cordon-until annotation of the App CR. The
+// app-operator annotation checked by key.IsAppCordoned is preferred. The
+// chart-operator annotation returned by key.CordonUntil is the fallback.
+func CordonUntil(app v1alpha1.App) string {
+	if v, ok := app.Annotations[annotation.AppOperatorCordonUntil]; ok {
+		return v
+	}
+
+	return app.Annotations[annotation.ChartOperatorCordonUntil]
+}
+
+// DependsOn returns the names of the apps the App CR depends on according to
+// the depends-on annotation. Empty names are skipped.
+func DependsOn(app v1alpha1.App) []string {
+	var names []string
+	for _, name := range strings.Split(app.Annotations[AnnotationDependsOn], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// IsAppPaused returns true if the App CR is not reconciled by app-operator
+// because the paused annotation is present.
+func IsAppPaused(app v1alpha1.App) bool {
+	_, ok := app.Annotations[annotation.AppOperatorPaused]
+	return ok
+}
 
 // formatVersion normalizes version representation by removing `v` prefix.
 // It matters for customers Catalogs, ACEs and apps created out of them.
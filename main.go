@@ -2,17 +2,20 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/microkit/command"
 	microserver "github.com/giantswarm/microkit/server"
 	"github.com/giantswarm/micrologger"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/giantswarm/app-exporter/flag"
 	"github.com/giantswarm/app-exporter/pkg/project"
 	"github.com/giantswarm/app-exporter/server"
 	"github.com/giantswarm/app-exporter/service"
+	"github.com/giantswarm/app-exporter/service/collector"
 )
 
 var (
@@ -103,14 +106,22 @@ func mainE(ctx context.Context) error {
 
 	daemonCommand.PersistentFlags().String(f.Service.Collector.Apps.AppTeamMappings, "", "The mapping of retired teams to new teams for alerting.")
 	daemonCommand.PersistentFlags().String(f.Service.Collector.Apps.DefaultTeam, "honeybadger", "The default team for alerting.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Collector.Apps.KnownTeams, nil, "Teams alerts can be routed to. Apps attributed to other teams are reported. When empty every team is valid.")
 	daemonCommand.PersistentFlags().String(f.Service.Collector.Apps.RetiredTeams, "", "The mapping of retired teams to new teams for alerting.")
+	daemonCommand.PersistentFlags().String(f.Service.Collector.Apps.TeamMappingsFile, "", "YAML file with appTeamMappings, retiredTeams and knownTeams. It is reloaded on change and must not be set together with these flags.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Collector.Apps.UnknownTeamFallback, false, "Whether to attribute apps to the default team if their team is not one of the known teams.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Collector.Catalogs.Allowlist, nil, "Catalogs to always check for upgrades. Entries are a catalog name or namespace/name.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Collector.Catalogs.Denylist, nil, "Catalogs to never check for upgrades. Entries are a catalog name or namespace/name.")
+	daemonCommand.PersistentFlags().String(f.Service.Collector.Catalogs.LabelSelector, collector.DefaultCatalogLabelSelector, "Label selector of the catalogs to check for upgrades.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Collector.ConfigReferences.Enabled, false, "Whether to check that the ConfigMaps and Secrets referenced by App CRs exist. Requires list and watch on ConfigMaps and Secrets.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Collector.KubeConfigSecrets.Enabled, false, "Whether to check that the kubeconfig secrets of apps installed to workload clusters exist. Requires list and watch on Secrets.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Collector.Events.Enabled, false, "Whether to emit Kubernetes events on App CRs when their health changes.")
 	daemonCommand.PersistentFlags().String(f.Service.Collector.Provider.Kind, "", "Provider of the management cluster. One of aws, azure, kvm.")
-	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.Address, "http://127.0.0.1:6443", "Address used to connect to Kubernetes. When empty in-cluster config is created.")
-	daemonCommand.PersistentFlags().Bool(f.Service.Kubernetes.InCluster, false, "Whether to use the in-cluster config to authenticate with Kubernetes.")
-	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.KubeConfig, "", "KubeConfig used to connect to Kubernetes. When empty other settings are used.")
-	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CAFile, "", "Certificate authority file path to use to authenticate with Kubernetes.")
-	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CrtFile, "", "Certificate file path to use to authenticate with Kubernetes.")
-	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.KeyFile, "", "Key file path to use to authenticate with Kubernetes.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Collector.Timeout, 40*time.Second, "Timeout of a single collection. When exceeded the metrics of the last collection are served. Should be lower than the scrape timeout.")
+	daemonCommand.PersistentFlags().String(f.Service.Replay.Dir, "", "Directory of YAML manifests to serve metrics from instead of connecting to Kubernetes.")
+	addKubernetesFlags(daemonCommand.PersistentFlags())
+
+	newCommand.CobraCommand().AddCommand(newSnapshotCommand(ctx, logger))
 
 	err = newCommand.CobraCommand().Execute()
 	if err != nil {
@@ -119,3 +130,16 @@ func mainE(ctx context.Context) error {
 
 	return nil
 }
+
+// addKubernetesFlags registers the flags to connect to Kubernetes and select
+// the collected objects. They are shared by the daemon and snapshot commands.
+func addKubernetesFlags(fs *pflag.FlagSet) {
+	fs.String(f.Service.Kubernetes.Address, "http://127.0.0.1:6443", "Address used to connect to Kubernetes. When empty in-cluster config is created.")
+	fs.Bool(f.Service.Kubernetes.InCluster, false, "Whether to use the in-cluster config to authenticate with Kubernetes.")
+	fs.String(f.Service.Kubernetes.KubeConfig, "", "KubeConfig used to connect to Kubernetes. When empty other settings are used.")
+	fs.String(f.Service.Kubernetes.TLS.CAFile, "", "Certificate authority file path to use to authenticate with Kubernetes.")
+	fs.String(f.Service.Kubernetes.TLS.CrtFile, "", "Certificate file path to use to authenticate with Kubernetes.")
+	fs.String(f.Service.Kubernetes.TLS.KeyFile, "", "Key file path to use to authenticate with Kubernetes.")
+	fs.String(f.Service.Kubernetes.Watch.LabelSelector, "", "Label selector App CRs must match to be collected. When empty all App CRs are collected.")
+	fs.StringSlice(f.Service.Kubernetes.Watch.Namespace, nil, "Namespaces to collect from. When empty all namespaces are collected.")
+}
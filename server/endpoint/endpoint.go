@@ -6,6 +6,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/app-exporter/server/endpoint/team"
 	"github.com/giantswarm/app-exporter/service"
 )
 
@@ -16,6 +17,7 @@ type Config struct {
 
 type Endpoint struct {
 	Healthz *healthz.Endpoint
+	Team    *team.Endpoint
 	Version *version.Endpoint
 }
 
@@ -34,6 +36,19 @@ func New(config Config) (*Endpoint, error) {
 		}
 	}
 
+	var teamEndpoint *team.Endpoint
+	{
+		c := team.Config{
+			Logger:  config.Logger,
+			Service: config.Service,
+		}
+
+		teamEndpoint, err = team.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var versionEndpoint *version.Endpoint
 	{
 		c := version.Config{
@@ -49,6 +64,7 @@ func New(config Config) (*Endpoint, error) {
 
 	e := &Endpoint{
 		Healthz: healthzEndpoint,
+		Team:    teamEndpoint,
 		Version: versionEndpoint,
 	}
 
@@ -0,0 +1,116 @@
+// Package team implements an endpoint explaining which team an App CR is
+// attributed to, e.g. to debug alerts routed to the wrong team.
+package team
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+
+	"github.com/giantswarm/app-exporter/service/collector"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "GET"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "team"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/teams/{namespace}/{name}"
+)
+
+// Explainer explains the team of an App CR. Errors matching
+// collector.IsNotFound are served as 404.
+type Explainer interface {
+	ExplainTeam(ctx context.Context, namespace, name string) (collector.TeamExplanation, error)
+}
+
+// Config represents the configuration used to create a team endpoint.
+type Config struct {
+	Logger  micrologger.Logger
+	Service Explainer
+}
+
+// Request identifies the App CR to explain.
+type Request struct {
+	Name      string
+	Namespace string
+}
+
+// New creates a new configured team endpoint.
+func New(config Config) (*Endpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Service == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Service must not be empty", config)
+	}
+
+	e := &Endpoint{
+		logger:  config.Logger,
+		service: config.Service,
+	}
+
+	return e, nil
+}
+
+type Endpoint struct {
+	logger  micrologger.Logger
+	service Explainer
+}
+
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		vars := mux.Vars(r)
+
+		request := Request{
+			Name:      vars["name"],
+			Namespace: vars["namespace"],
+		}
+
+		return request, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r := request.(Request)
+
+		response, err := e.service.ExplainTeam(ctx, r.Namespace, r.Name)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return response, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
@@ -0,0 +1,130 @@
+package team
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/gorilla/mux"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/app-exporter/service/collector"
+)
+
+func Test_Endpoint(t *testing.T) {
+	explanation := collector.TeamExplanation{
+		Name:      "hello-world-app",
+		Namespace: "giantswarm",
+		Candidates: []collector.TeamCandidate{
+			{Source: collector.TeamSourceDefault, Team: "honeybadger"},
+		},
+		Decision: collector.TeamDecision{
+			Source: collector.TeamSourceDefault,
+			Team:   "honeybadger",
+		},
+	}
+
+	s := runtime.NewScheme()
+	err := v1alpha1.AddToScheme(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-world-app",
+			Namespace: "giantswarm",
+		},
+		Spec: v1alpha1.AppSpec{
+			Catalog: "giantswarm",
+			Name:    "hello-world-app",
+			Version: "0.3.0",
+		},
+	}
+
+	// The collector is used as the explainer so the endpoint returns its
+	// errors, which are mapped to status codes by the server.
+	explainer, err := collector.NewApp(collector.AppConfig{
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: clientfake.NewClientBuilder().
+				WithScheme(s).
+				WithRuntimeObjects(app).
+				Build(),
+		}),
+		Logger: microloggertest.New(),
+
+		DefaultTeam:         "honeybadger",
+		Provider:            "aws",
+		RetiredTeamsMapping: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	e, err := New(Config{
+		Logger:  microloggertest.New(),
+		Service: explainer,
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	testCases := []struct {
+		name         string
+		vars         map[string]string
+		expected     interface{}
+		errorMatcher func(error) bool
+	}{
+		{
+			name: "case 0: app exists",
+			vars: map[string]string{
+				"namespace": "giantswarm",
+				"name":      "hello-world-app",
+			},
+			expected: explanation,
+		},
+		{
+			name: "case 1: app does not exist",
+			vars: map[string]string{
+				"namespace": "giantswarm",
+				"name":      "missing",
+			},
+			errorMatcher: collector.IsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := mux.SetURLVars(httptest.NewRequest(Method, "/", nil), tc.vars)
+
+			request, err := e.Decoder()(context.TODO(), r)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			response, err := e.Endpoint()(context.TODO(), request)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(response, tc.expected) {
+				t.Fatalf("response == %#v, want %#v", response, tc.expected)
+			}
+		})
+	}
+}
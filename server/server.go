@@ -15,6 +15,7 @@ import (
 	"github.com/giantswarm/app-exporter/pkg/project"
 	"github.com/giantswarm/app-exporter/server/endpoint"
 	"github.com/giantswarm/app-exporter/service"
+	"github.com/giantswarm/app-exporter/service/collector"
 )
 
 type Config struct {
@@ -51,6 +52,7 @@ func New(config Config) (microserver.Server, error) {
 
 			Endpoints: []microserver.Endpoint{
 				endpointCollection.Healthz,
+				endpointCollection.Team,
 				endpointCollection.Version,
 			},
 			ErrorEncoder: encodeError,
@@ -91,6 +93,13 @@ func encodeError(ctx context.Context, err error, w http.ResponseWriter) {
 	rErr := err.(microserver.ResponseError)
 	uErr := rErr.Underlying()
 
+	if collector.IsNotFound(uErr) {
+		rErr.SetCode(microserver.CodeResourceNotFound)
+		rErr.SetMessage(uErr.Error())
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
 	rErr.SetCode(microserver.CodeInternalError)
 	rErr.SetMessage(uErr.Error())
 	w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	microserver "github.com/giantswarm/microkit/server"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/app-exporter/service/collector"
+)
+
+func Test_encodeError(t *testing.T) {
+	s := runtime.NewScheme()
+	err := v1alpha1.AddToScheme(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := collector.NewApp(collector.AppConfig{
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: clientfake.NewClientBuilder().
+				WithScheme(s).
+				Build(),
+		}),
+		Logger: microloggertest.New(),
+
+		DefaultTeam:         "honeybadger",
+		Provider:            "aws",
+		RetiredTeamsMapping: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	_, notFound := app.ExplainTeam(context.TODO(), "giantswarm", "missing")
+	if !collector.IsNotFound(notFound) {
+		t.Fatalf("error == %#v, want notFoundError", notFound)
+	}
+
+	testCases := []struct {
+		name           string
+		err            error
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:           "case 0: missing app",
+			err:            notFound,
+			expectedCode:   microserver.CodeResourceNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "case 1: other error",
+			err:            errors.New("injected error"),
+			expectedCode:   microserver.CodeInternalError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rErr, err := microserver.NewResponseError(microserver.ResponseErrorConfig{Underlying: tc.err})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			encodeError(context.TODO(), rErr, w)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("status == %d, want %d", w.Code, tc.expectedStatus)
+			}
+			if rErr.Code() != tc.expectedCode {
+				t.Fatalf("code == %#q, want %#q", rErr.Code(), tc.expectedCode)
+			}
+		})
+	}
+}
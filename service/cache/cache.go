@@ -0,0 +1,227 @@
+// Package cache provides an informer backed cache for the Kubernetes
+// resources read by the collectors. Scrapes are served from memory so the API
+// server only sees watches instead of full List calls on every scrape.
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/app-exporter/internal/key"
+	"github.com/giantswarm/app-exporter/pkg/project"
+)
+
+// Config represents the configuration used to create a new cache.
+type Config struct {
+	K8sClient k8sclient.Interface
+	Logger    micrologger.Logger
+
+	// AppLabelSelector restricts the cached App CRs. Optional.
+	AppLabelSelector labels.Selector
+	// ConfigReferences caches the metadata of ConfigMaps and Secrets to
+	// check the config references of App CRs.
+	ConfigReferences bool
+	// KubeConfigSecrets caches the metadata of Secrets to check the
+	// kubeconfig secrets of App CRs.
+	KubeConfigSecrets bool
+	// Namespaces restricts the cache to the given namespaces. When empty
+	// all namespaces are cached.
+	Namespaces []string
+}
+
+// Cache wraps a controller-runtime cache with informers for every resource
+// the collectors read.
+type Cache struct {
+	cache  ctrlcache.Cache
+	logger micrologger.Logger
+
+	metadataKinds []string
+}
+
+// New creates a new configured cache. Informers are only started once Boot
+// is called.
+func New(config Config) (*Cache, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	ctrlCache, err := ctrlcache.New(config.K8sClient.RESTConfig(), newOptions(config))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c := &Cache{
+		cache:  ctrlCache,
+		logger: config.Logger,
+
+		metadataKinds: metadataKinds(config),
+	}
+
+	return c, nil
+}
+
+// newOptions returns the options of the controller-runtime cache for the given
+// config.
+func newOptions(config Config) ctrlcache.Options {
+	var defaultNamespaces map[string]ctrlcache.Config
+	if len(config.Namespaces) > 0 {
+		defaultNamespaces = map[string]ctrlcache.Config{}
+		for _, ns := range config.Namespaces {
+			defaultNamespaces[ns] = ctrlcache.Config{}
+		}
+	}
+
+	o := ctrlcache.Options{
+		Scheme: config.K8sClient.Scheme(),
+
+		ByObject: map[client.Object]ctrlcache.ByObject{
+			&v1alpha1.App{}: {
+				Label: config.AppLabelSelector,
+			},
+			// Only app-operator deployments are relevant, so we do not
+			// cache all deployments of the cluster.
+			&appsv1.Deployment{}: {
+				Label: labels.SelectorFromSet(labels.Set{
+					label.App: project.OperatorName(),
+				}),
+			},
+		},
+		DefaultNamespaces: defaultNamespaces,
+		DefaultTransform:  ctrlcache.TransformStripManagedFields(),
+		// Reading a resource without an informer is a bug. Fail instead
+		// of silently starting a new cluster wide watch.
+		ReaderFailOnMissingInformer: true,
+	}
+
+	for _, kind := range metadataKinds(config) {
+		o.ByObject[NewMetadata(kind)] = ctrlcache.ByObject{
+			Transform: stripMetadata,
+		}
+	}
+
+	return o
+}
+
+// metadataKinds returns the core kinds of which only the metadata is cached.
+// ConfigMaps are only needed for the config references, Secrets for the
+// config references and the kubeconfig secrets.
+func metadataKinds(config Config) []string {
+	var kinds []string
+	if config.ConfigReferences {
+		kinds = append(kinds, key.KindConfigMap)
+	}
+	if config.ConfigReferences || config.KubeConfigSecrets {
+		kinds = append(kinds, key.KindSecret)
+	}
+
+	return kinds
+}
+
+// stripMetadata drops everything but the identity of ConfigMaps and Secrets
+// as the collectors only check whether they exist. Their labels and
+// annotations, e.g. kubectl's last applied configuration, would otherwise
+// make up most of the memory of the cache.
+func stripMetadata(obj interface{}) (interface{}, error) {
+	m, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return obj, nil
+	}
+
+	stripped := &metav1.PartialObjectMetadata{
+		TypeMeta: m.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            m.Name,
+			Namespace:       m.Namespace,
+			ResourceVersion: m.ResourceVersion,
+			UID:             m.UID,
+		},
+	}
+
+	return stripped, nil
+}
+
+// SyncTimeout is how long Boot waits for the informers to sync. Informers
+// which cannot list their resources, e.g. because of missing RBAC
+// permissions, retry forever, so the sync must be bounded for the failure to
+// surface.
+const SyncTimeout = 5 * time.Minute
+
+// Boot registers the informers, starts them and blocks until they are synced.
+// It returns an error if they do not sync within SyncTimeout or the given
+// context is cancelled.
+func (c *Cache) Boot(ctx context.Context) error {
+	for _, obj := range Objects(c.metadataKinds) {
+		_, err := c.cache.GetInformer(ctx, obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	go func() {
+		err := c.cache.Start(ctx)
+		if err != nil {
+			c.logger.Errorf(ctx, err, "failed to start cache")
+		}
+	}()
+
+	c.logger.Debugf(ctx, "waiting for cache to sync")
+
+	syncCtx, cancel := context.WithTimeout(ctx, SyncTimeout)
+	defer cancel()
+
+	if !c.cache.WaitForCacheSync(syncCtx) {
+		return microerror.Maskf(executionFailedError, "cache did not sync within %s", SyncTimeout)
+	}
+
+	c.logger.Debugf(ctx, "cache synced")
+
+	return nil
+}
+
+// Reader returns a client.Reader serving Get and List calls from memory.
+func (c *Cache) Reader() client.Reader {
+	return c.cache
+}
+
+// Objects returns the resources the collectors read and which are therefore
+// cached. Of the given core kinds, e.g. ConfigMaps and Secrets, only the
+// metadata is cached as the collectors only check whether they exist.
+func Objects(metadataKinds []string) []client.Object {
+	objects := []client.Object{
+		&v1alpha1.App{},
+		&v1alpha1.AppCatalogEntry{},
+		&v1alpha1.Catalog{},
+		&appsv1.Deployment{},
+	}
+
+	for _, kind := range metadataKinds {
+		objects = append(objects, NewMetadata(kind))
+	}
+
+	return objects
+}
+
+// NewMetadata returns an object to read only the metadata of the given core
+// kind, e.g. ConfigMap or Secret. Reading it never reads the data of the
+// object.
+func NewMetadata(kind string) *metav1.PartialObjectMetadata {
+	m := &metav1.PartialObjectMetadata{}
+	m.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+
+	return m
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+func Test_newOptions(t *testing.T) {
+	testCases := []struct {
+		name               string
+		appLabelSelector   string
+		configReferences   bool
+		kubeConfigSecrets  bool
+		namespaces         []string
+		expectedNamespaces []string
+		expectedMetadata   []string
+	}{
+		{
+			name: "case 0: all namespaces",
+		},
+		{
+			name:               "case 1: scoped to namespaces",
+			namespaces:         []string{"giantswarm", "org-acme"},
+			expectedNamespaces: []string{"giantswarm", "org-acme"},
+		},
+		{
+			name:             "case 2: app label selector",
+			appLabelSelector: "giantswarm.io/cluster=foo",
+		},
+		{
+			name:               "case 3: config references",
+			configReferences:   true,
+			namespaces:         []string{"giantswarm"},
+			expectedNamespaces: []string{"giantswarm"},
+			expectedMetadata:   []string{"ConfigMap", "Secret"},
+		},
+		{
+			name:              "case 4: kubeconfig secrets",
+			kubeConfigSecrets: true,
+			expectedMetadata:  []string{"Secret"},
+		},
+		{
+			name:               "case 5: config references and kubeconfig secrets",
+			configReferences:   true,
+			kubeConfigSecrets:  true,
+			namespaces:         []string{"giantswarm"},
+			expectedNamespaces: []string{"giantswarm"},
+			expectedMetadata:   []string{"ConfigMap", "Secret"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appLabelSelector, err := labels.Parse(tc.appLabelSelector)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := Config{
+				K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{}),
+
+				AppLabelSelector:  appLabelSelector,
+				ConfigReferences:  tc.configReferences,
+				KubeConfigSecrets: tc.kubeConfigSecrets,
+				Namespaces:        tc.namespaces,
+			}
+
+			o := newOptions(config)
+
+			if !o.ReaderFailOnMissingInformer {
+				t.Fatalf("ReaderFailOnMissingInformer == false, want true")
+			}
+
+			var namespaces []string
+			for ns := range o.DefaultNamespaces {
+				namespaces = append(namespaces, ns)
+			}
+			sort.Strings(namespaces)
+			if !reflect.DeepEqual(namespaces, tc.expectedNamespaces) {
+				t.Fatalf("namespaces == %v, want %v", namespaces, tc.expectedNamespaces)
+			}
+
+			var appSelector, deploymentSelector labels.Selector
+			var metadata []string
+			for obj, byObject := range o.ByObject {
+				switch obj.(type) {
+				case *v1alpha1.App:
+					appSelector = byObject.Label
+				case *appsv1.Deployment:
+					deploymentSelector = byObject.Label
+				case *metav1.PartialObjectMetadata:
+					if byObject.Transform == nil {
+						t.Fatalf("transform of %T == nil, want stripMetadata", obj)
+					}
+					metadata = append(metadata, obj.GetObjectKind().GroupVersionKind().Kind)
+				}
+			}
+
+			sort.Strings(metadata)
+			if !reflect.DeepEqual(metadata, tc.expectedMetadata) {
+				t.Fatalf("metadata objects == %v, want %v", metadata, tc.expectedMetadata)
+			}
+			if got := len(Objects(metadataKinds(config))); got != 4+len(tc.expectedMetadata) {
+				t.Fatalf("objects == %d, want %d", got, 4+len(tc.expectedMetadata))
+			}
+
+			if appSelector.String() != appLabelSelector.String() {
+				t.Fatalf("app label selector == %#q, want %#q", appSelector, appLabelSelector)
+			}
+			if deploymentSelector.String() != "app=app-operator" {
+				t.Fatalf("deployment label selector == %#q, want %#q", deploymentSelector, "app=app-operator")
+			}
+		})
+	}
+}
+
+func Test_stripMetadata(t *testing.T) {
+	m := NewMetadata("Secret")
+	m.Name = "hello-world-user-secrets"
+	m.Namespace = "org-acme"
+	m.ResourceVersion = "42"
+	m.Labels = map[string]string{"owner": "helm"}
+	m.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+
+	obj, err := stripMetadata(m)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	expected := NewMetadata("Secret")
+	expected.Name = "hello-world-user-secrets"
+	expected.Namespace = "org-acme"
+	expected.ResourceVersion = "42"
+
+	if !reflect.DeepEqual(obj, expected) {
+		t.Fatalf("object == %#v, want %#v", obj, expected)
+	}
+}
+
+func Test_Reader(t *testing.T) {
+	schemeBuilder := runtime.SchemeBuilder{
+		v1alpha1.AddToScheme,
+	}
+
+	err := schemeBuilder.AddToScheme(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing listens here so the cache must fail before talking to the
+	// API. A static mapper is used as the default one discovers the API.
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range []schema.GroupVersionKind{
+		v1alpha1.SchemeGroupVersion.WithKind("App"),
+		appsv1.SchemeGroupVersion.WithKind("Deployment"),
+		corev1.SchemeGroupVersion.WithKind("Pod"),
+	} {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+
+	o := newOptions(Config{
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{}),
+
+		Namespaces: []string{"giantswarm"},
+	})
+	o.Mapper = mapper
+
+	c, err := ctrlcache.New(&rest.Config{Host: "https://127.0.0.1:1"}, o)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	// Pods have no informer so reading them must not start a new watch.
+	err = c.Get(context.TODO(), types.NamespacedName{Namespace: "giantswarm", Name: "test"}, &corev1.Pod{})
+	var notCached *ctrlcache.ErrResourceNotCached
+	if !errors.As(err, &notCached) {
+		t.Fatalf("error == %#v, want ErrResourceNotCached", err)
+	}
+
+	// Namespaces which are not watched cannot be read either.
+	err = c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "test"}, &v1alpha1.App{})
+	if err == nil {
+		t.Fatalf("error == nil, want error for unwatched namespace")
+	}
+}
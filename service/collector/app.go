@@ -2,11 +2,14 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	"github.com/giantswarm/app/v7/pkg/key"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
@@ -19,10 +22,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	expkey "github.com/giantswarm/app-exporter/internal/key"
+	"github.com/giantswarm/app-exporter/service/cache"
 )
 
 var (
@@ -48,6 +53,212 @@ var (
 		nil,
 	)
 
+	appUpgradeAvailableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "upgrade_available"),
+		"Apps with a newer version in their catalog by kind of upgrade.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelCatalog,
+			labelKind,
+		},
+		nil,
+	)
+
+	appVersionsBehindDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "versions_behind"),
+		"Number of released versions in the catalog between the version of the app and the latest version by kind of upgrade.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelCatalog,
+			labelTeam,
+			labelKind,
+		},
+		nil,
+	)
+
+	appLastDeployedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "last_deployed_timestamp_seconds"),
+		"Time the release of the app was last deployed in unix seconds.",
+		[]string{
+			labelName,
+			labelNamespace,
+		},
+		nil,
+	)
+
+	appReleaseStatusAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "release_status_age_seconds"),
+		"Seconds since the app is in its current release status.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelStatus,
+		},
+		nil,
+	)
+
+	appStatusTransitionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "status_transitions_total"),
+		"Number of observed changes of the release status of the app.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelFrom,
+			labelTo,
+		},
+		nil,
+	)
+
+	appStatusLastTransitionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "status_last_transition_timestamp_seconds"),
+		"Time of the last observed change of the release status of the app in unix seconds.",
+		[]string{
+			labelName,
+			labelNamespace,
+		},
+		nil,
+	)
+
+	appFailureReasonDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "failure_reason"),
+		"Class of the reason why the release of the app is not deployed.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelReasonClass,
+		},
+		nil,
+	)
+
+	appCatalogEntryMissingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "catalog_entry_missing"),
+		"Apps whose version has no AppCatalogEntry CR in their catalog.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelCatalog,
+			labelVersion,
+		},
+		nil,
+	)
+
+	appCatalogMissingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "catalog_missing"),
+		"Apps referencing a catalog which does not exist in the catalog namespace of the app.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelCatalog,
+			labelCatalogNamespace,
+			labelReason,
+		},
+		nil,
+	)
+
+	appConfigReferenceMissingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "config_reference_missing"),
+		"ConfigMaps and Secrets referenced by apps which do not exist.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelKind,
+			labelRefNamespace,
+			labelRefName,
+		},
+		nil,
+	)
+
+	appKubeConfigSecretMissingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "kubeconfig_secret_missing"),
+		"Whether the kubeconfig secret of apps installed to a workload cluster is missing.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelClusterId,
+			labelClusterMissing,
+			labelRefNamespace,
+			labelRefName,
+		},
+		nil,
+	)
+
+	appDependencyUnmetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "dependency_unmet"),
+		"Dependencies of apps which are missing or not deployed.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelDependency,
+		},
+		nil,
+	)
+
+	appDependencyCycleDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "dependency_cycle"),
+		"Apps which are part of a dependency cycle with the sorted names of all apps in the cycle.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelCycle,
+		},
+		nil,
+	)
+
+	appUnknownTeamDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "unknown_team"),
+		"Apps attributed to a team which is not one of the known teams.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelTeam,
+		},
+		nil,
+	)
+
+	appPausedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "paused"),
+		"Apps which are not reconciled by app-operator because they are paused.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelTeam,
+		},
+		nil,
+	)
+
+	appCordonInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "cordon_info"),
+		"Cordoned apps with the reason of the cordon truncated to 64 characters.",
+		[]string{
+			labelName,
+			labelNamespace,
+			labelReason,
+		},
+		nil,
+	)
+
+	appCordonExpiredDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "cordon_expired"),
+		"Whether the cordon of the app has expired but its annotations were not removed.",
+		[]string{
+			labelName,
+			labelNamespace,
+		},
+		nil,
+	)
+
+	appCordonUntilParseErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "app", "cordon_until_parse_errors_total"),
+		"Number of collections in which the cordon-until annotation of the app could not be parsed.",
+		[]string{
+			labelName,
+			labelNamespace,
+		},
+		nil,
+	)
+
 	appCordonExpireTimeDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "app", "cordon_expire_time_seconds"),
 		"A metric of the expire time of cordoned apps unix seconds.",
@@ -59,26 +270,63 @@ var (
 	)
 )
 
+const (
+	catalogMissingReasonNotFound       = "not-found"
+	catalogMissingReasonWrongNamespace = "wrong-namespace"
+)
+
 // AppConfig is this collector's configuration struct.
 type AppConfig struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
-
-	AppTeamMappings     map[string]string
-	DefaultTeam         string
-	Provider            string
-	RetiredTeamsMapping map[string]string
+	// EventRecorder is used to emit events on App CRs when their health
+	// changes. When empty no events are emitted.
+	EventRecorder record.EventRecorder
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+	// Reader is used for all Get and List calls. Usually this is backed by
+	// the informer cache. When empty the controller-runtime client of
+	// K8sClient is used and every scrape hits the API server.
+	Reader client.Reader
+
+	AppTeamMappings      map[string]string
+	CatalogAllowlist     []string
+	CatalogDenylist      []string
+	CatalogLabelSelector labels.Selector
+	ConfigReferences     bool
+	DefaultTeam          string
+	KnownTeams           []string
+	KubeConfigSecrets    bool
+	LabelSelector        labels.Selector
+	Namespaces           []string
+	Provider             string
+	RetiredTeamsMapping  map[string]string
+	Timeout              time.Duration
+	UnknownTeamFallback  bool
 }
 
 // App is the main struct for this collector.
 type App struct {
+	events    *appEvents
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
-
-	appTeamMappings     map[string]string
-	defaultTeam         string
-	provider            string
-	retiredTeamsMapping map[string]string
+	reader    client.Reader
+
+	catalogAllowlist       []string
+	catalogDenylist        []string
+	catalogLabelSelector   labels.Selector
+	configReferences       bool
+	cordonUntilParseErrors appCounter
+	defaultTeam            string
+	kubeConfigSecrets      bool
+	labelSelector          labels.Selector
+	namespaces             []string
+	provider               string
+	snapshot               snapshot
+	statuses               statusTracker
+	teamMappings           atomic.Pointer[TeamMappings]
+	timeout                time.Duration
+	unknownTeamFallback    bool
+
+	now func() time.Time
 }
 
 // NewApp creates a new App metrics collector
@@ -100,24 +348,67 @@ func NewApp(config AppConfig) (*App, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.RetiredTeamsMapping must not be empty", config)
 	}
 
+	reader := config.Reader
+	if reader == nil {
+		reader = config.K8sClient.CtrlClient()
+	}
+
+	catalogLabelSelector := config.CatalogLabelSelector
+	if catalogLabelSelector == nil {
+		var err error
+		catalogLabelSelector, err = labels.Parse(DefaultCatalogLabelSelector)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	a := &App{
+		events:    newAppEvents(config.EventRecorder),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+		reader:    reader,
+
+		catalogAllowlist:     config.CatalogAllowlist,
+		catalogDenylist:      config.CatalogDenylist,
+		catalogLabelSelector: catalogLabelSelector,
+		configReferences:     config.ConfigReferences,
+		defaultTeam:          config.DefaultTeam,
+		kubeConfigSecrets:    config.KubeConfigSecrets,
+		labelSelector:        config.LabelSelector,
+		namespaces:           config.Namespaces,
+		provider:             config.Provider,
+		timeout:              config.Timeout,
+		unknownTeamFallback:  config.UnknownTeamFallback,
+
+		now: time.Now,
+	}
 
-		appTeamMappings:     config.AppTeamMappings,
-		defaultTeam:         config.DefaultTeam,
-		provider:            config.Provider,
-		retiredTeamsMapping: config.RetiredTeamsMapping,
+	teamMappings := TeamMappings{
+		AppTeams:     config.AppTeamMappings,
+		RetiredTeams: config.RetiredTeamsMapping,
+		KnownTeams:   config.KnownTeams,
 	}
 
+	err := teamMappings.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	a.SetTeamMappings(teamMappings)
+
 	return a, nil
 }
 
 // Collect is the main metrics collection function.
 func (a *App) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
+	ch <- prometheus.MustNewConstMetric(
+		teamMappingsInfoDesc,
+		prometheus.GaugeValue,
+		gaugeValue,
+		a.teamMappings.Load().Hash(),
+	)
 
-	err := a.collectAppStatus(ctx, ch)
+	err := collectWithTimeout(a.logger, collectorApp, a.timeout, &a.snapshot, ch, a.collectAppStatus)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -128,42 +419,99 @@ func (a *App) Collect(ch chan<- prometheus.Metric) error {
 // Describe emits the description for the metrics collected here.
 func (a *App) Describe(ch chan<- *prometheus.Desc) error {
 	ch <- appDesc
+	ch <- appUpgradeAvailableDesc
+	ch <- appVersionsBehindDesc
+	ch <- appLastDeployedDesc
+	ch <- appReleaseStatusAgeDesc
+	ch <- appStatusTransitionsDesc
+	ch <- appStatusLastTransitionDesc
+	ch <- appFailureReasonDesc
+	ch <- appCatalogMissingDesc
+	ch <- appCatalogEntryMissingDesc
+	ch <- appConfigReferenceMissingDesc
+	ch <- appKubeConfigSecretMissingDesc
+	ch <- appDependencyUnmetDesc
+	ch <- appDependencyCycleDesc
+	ch <- appUnknownTeamDesc
+	ch <- appPausedDesc
+	ch <- appCordonInfoDesc
+	ch <- appCordonExpiredDesc
+	ch <- appCordonUntilParseErrorsDesc
 	ch <- appCordonExpireTimeDesc
+	ch <- teamMappingsInfoDesc
 	return nil
 }
 
-func (a *App) collectAppStatus(ctx context.Context, ch chan<- prometheus.Metric) error {
-	apps := &v1alpha1.AppList{}
-	err := a.k8sClient.CtrlClient().List(ctx, apps)
+func (a *App) collectAppStatus(ctx context.Context, c *collection, ch chan<- prometheus.Metric) error {
+	apps, err := listApps(ctx, a.reader, a.namespaces, a.labelSelector)
 	if err != nil {
+		// collectWithTimeout records collections which hit their deadline
+		// as timeouts already.
+		if !errors.Is(err, context.DeadlineExceeded) {
+			recordError(collectorApp, stageListApps)
+		}
 		return microerror.Mask(err)
 	}
 
-	latestAppVersions, err := a.getLatestAppVersions(ctx)
+	// Failed lookups of the latest versions and teams must not fail the whole
+	// collection. We emit what could be computed and fall back to an empty
+	// latest version or the default team.
+	degraded := false
+
+	// The catalogs are only known when all of them could be listed.
+	catalogs, err := a.listCatalogs(ctx)
+	catalogsKnown := err == nil
 	if err != nil {
-		return microerror.Mask(err)
+		a.logger.Errorf(ctx, err, "failed to list all catalogs")
+		recordError(collectorApp, stageCatalogs)
+		degraded = true
 	}
 
-	teamMappings, err := a.getTeamMappings(ctx, apps.Items)
+	catalogAppVersions, err := a.getCatalogAppVersions(ctx, catalogs)
 	if err != nil {
-		return microerror.Mask(err)
+		a.logger.Errorf(ctx, err, "failed to get all latest app versions")
+		recordError(collectorApp, stageLatestVersions)
+		degraded = true
 	}
 
-	for _, app := range apps.Items {
-		appCatalogEntryName := key.AppCatalogEntryName(key.CatalogName(app), key.AppName(app), key.Version(app))
-		team := teamMappings[appCatalogEntryName]
-		if team == "" {
-			// Set the default team if there is no mapping.
-			team = a.defaultTeam
-		}
+	// The team mappings are loaded once so a reload does not mix old and
+	// new mappings within one collection.
+	mappings := a.teamMappings.Load()
 
-		// Team annotation on the App CR overrides if it exists.
-		if key.AppTeam(app) != "" {
-			team = formatTeamName(key.AppTeam(app))
-		}
+	teamMappings, err := a.getTeamMappings(ctx, apps, mappings)
+	if err != nil {
+		a.logger.Errorf(ctx, err, "failed to get all team mappings")
+		recordError(collectorApp, stageTeams)
+		degraded = true
+	}
+
+	// Dependencies are resolved among the listed App CRs. With a label
+	// selector some App CRs are not listed so missing dependencies cannot be
+	// told apart from filtered ones.
+	dependencies := newDependencyGraph(apps)
+	dependencyCycles := dependencies.cycles()
+	dependenciesComplete := a.labelSelector == nil || a.labelSelector.Empty()
 
-		if v, ok := app.Labels[annotation.AppTeam]; ok {
-			team = formatTeamName(v)
+	now := a.now()
+	catalogEntriesFailed := false
+	configsFailed := false
+
+	for _, app := range apps {
+		appCatalogEntryName := key.AppCatalogEntryName(key.CatalogName(app), key.AppName(app), key.Version(app))
+		d := a.explainTeam(app, teamMappings[appCatalogEntryName], mappings).Decision
+		team := d.Team
+
+		// Alerts of apps attributed to teams which do not exist, e.g.
+		// because of a typo in the team annotation, are routed nowhere.
+		if d.UnknownTeam != "" {
+			ch <- prometheus.MustNewConstMetric(
+				appUnknownTeamDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				app.Name,
+				app.Namespace,
+				d.UnknownTeam,
+			)
 		}
 
 		// Trim `v` prefix from App CR version if there is any
@@ -185,8 +533,18 @@ func (a *App) collectAppStatus(ctx context.Context, ch chan<- prometheus.Metric)
 
 		// For optional apps in public catalogs we check if an upgrade
 		// is available.
-		latestVersion := latestAppVersions[fmt.Sprintf("%s-%s", key.CatalogName(app), key.AppName(app))]
-		upgradeAvailable := latestVersion != "" && latestVersion != appSpecVersion
+		var versions appVersions
+		var hasVersions bool
+		if catalog, ok := findCatalog(catalogs, app); ok {
+			versions, hasVersions = catalogAppVersions[catalogAppKey{
+				namespace: catalog.Namespace,
+				catalog:   catalog.Name,
+				app:       key.AppName(app),
+			}]
+		}
+		latestVersion := versions.latest
+		upgrade := upgradeKind(appSpecVersion, latestVersion)
+		upgradeAvailable := upgrade != ""
 
 		releaseStatus := app.Status.Release.Status
 		if releaseStatus == "" {
@@ -214,13 +572,244 @@ func (a *App) collectAppStatus(ctx context.Context, ch chan<- prometheus.Metric)
 			clusterId,
 		)
 
+		if upgradeAvailable {
+			ch <- prometheus.MustNewConstMetric(
+				appUpgradeAvailableDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				app.Name,
+				app.Namespace,
+				app.Spec.Catalog,
+				upgrade,
+			)
+		}
+
+		if hasVersions && latestVersion != "" {
+			behind, err := versionsBehind(appSpecVersion, latestVersion, versions.released)
+			if err != nil {
+				a.logger.Debugf(ctx, "could not count versions behind for app %#q in %#q: %s", app.Name, app.Namespace, err)
+			} else {
+				for _, kind := range []string{upgradeKindMajor, upgradeKindMinor, upgradeKindPatch} {
+					ch <- prometheus.MustNewConstMetric(
+						appVersionsBehindDesc,
+						prometheus.GaugeValue,
+						float64(behind[kind]),
+						app.Name,
+						app.Namespace,
+						app.Spec.Catalog,
+						team,
+						kind,
+					)
+				}
+			}
+		}
+
+		if lastDeployed := app.Status.Release.LastDeployed; !lastDeployed.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				appLastDeployedDesc,
+				prometheus.GaugeValue,
+				float64(lastDeployed.Unix()),
+				app.Name,
+				app.Namespace,
+			)
+		}
+
+		var observed observedStatus
+		err = c.update(func() {
+			observed = a.statuses.observe(app, releaseStatus, now)
+			a.events.statusObserved(&app, observed, now)
+			a.events.versionObserved(&app, appSpecVersion, appStatusVersion, now)
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			appReleaseStatusAgeDesc,
+			prometheus.GaugeValue,
+			now.Sub(observed.since).Seconds(),
+			app.Name,
+			app.Namespace,
+			releaseStatus,
+		)
+
+		for t, count := range observed.transitions {
+			ch <- prometheus.MustNewConstMetric(
+				appStatusTransitionsDesc,
+				prometheus.CounterValue,
+				float64(count),
+				app.Name,
+				app.Namespace,
+				t.from,
+				t.to,
+			)
+		}
+
+		if !observed.lastTransition.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				appStatusLastTransitionDesc,
+				prometheus.GaugeValue,
+				float64(observed.lastTransition.Unix()),
+				app.Name,
+				app.Namespace,
+			)
+		}
+
+		// The reason is free text so only its class is exposed to bound the
+		// cardinality.
+		if reason := app.Status.Release.Reason; reason != "" && releaseStatus != deployedStatus {
+			ch <- prometheus.MustNewConstMetric(
+				appFailureReasonDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				app.Name,
+				app.Namespace,
+				classifyFailureReason(releaseStatus, reason),
+			)
+		}
+
+		// app-operator cannot install apps whose catalog is missing. Catalogs
+		// existing in another namespace are reported separately as this
+		// usually means spec.catalogNamespace is wrong.
+		if catalogsKnown {
+			if reason := catalogMissingReason(catalogs, a.namespaces, app); reason != "" {
+				ch <- prometheus.MustNewConstMetric(
+					appCatalogMissingDesc,
+					prometheus.GaugeValue,
+					gaugeValue,
+					app.Name,
+					app.Namespace,
+					app.Spec.Catalog,
+					key.CatalogNamespace(app),
+					reason,
+				)
+			}
+		}
+
+		// Missing entries are only detected for existing catalogs. If the
+		// catalogs are not known we cannot tell.
+		if catalog, ok := findCatalog(catalogs, app); ok && catalogsKnown {
+			missing, err := a.isAppCatalogEntryMissing(ctx, app, catalog)
+			if err != nil {
+				a.logger.Errorf(ctx, err, "failed to check catalog entry of app %#q in %#q", app.Name, app.Namespace)
+				catalogEntriesFailed = true
+			} else if missing {
+				ch <- prometheus.MustNewConstMetric(
+					appCatalogEntryMissingDesc,
+					prometheus.GaugeValue,
+					gaugeValue,
+					app.Name,
+					app.Namespace,
+					app.Spec.Catalog,
+					app.Spec.Version,
+				)
+			}
+		}
+
+		// Reading ConfigMaps and Secrets requires access to all of them, so
+		// the config reference and kubeconfig secret checks are opt-in.
+		if a.configReferences {
+			// The next upgrade of the app fails when a referenced config was
+			// deleted.
+			missingRefs, err := a.getMissingConfigReferences(ctx, app)
+			if err != nil {
+				a.logger.Errorf(ctx, err, "failed to check config references of app %#q in %#q", app.Name, app.Namespace)
+				configsFailed = true
+			}
+			for _, ref := range missingRefs {
+				ch <- prometheus.MustNewConstMetric(
+					appConfigReferenceMissingDesc,
+					prometheus.GaugeValue,
+					gaugeValue,
+					app.Name,
+					app.Namespace,
+					ref.Kind,
+					ref.Namespace,
+					ref.Name,
+				)
+			}
+		}
+
+		// Apps for workload clusters can never be deployed without their
+		// kubeconfig secret. This happens e.g. during cluster deletion.
+		if a.kubeConfigSecrets {
+			ref, missing, known, err := a.isKubeConfigSecretMissing(ctx, app)
+			if err != nil {
+				a.logger.Errorf(ctx, err, "failed to check kubeconfig secret of app %#q in %#q", app.Name, app.Namespace)
+				configsFailed = true
+			} else if known {
+				ch <- prometheus.MustNewConstMetric(
+					appKubeConfigSecretMissingDesc,
+					prometheus.GaugeValue,
+					boolToFloat64(missing),
+					app.Name,
+					app.Namespace,
+					clusterId,
+					strconv.FormatBool(clusterMissing),
+					ref.Namespace,
+					ref.Name,
+				)
+			}
+		}
+
+		// app-operator does not install apps until all their dependencies
+		// are deployed. Apps in a dependency cycle are never installed.
+		for _, dependency := range dependencies.unmet(app, dependenciesComplete) {
+			ch <- prometheus.MustNewConstMetric(
+				appDependencyUnmetDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				app.Name,
+				app.Namespace,
+				dependency,
+			)
+		}
+		if cycle, ok := dependencyCycles[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				appDependencyCycleDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				app.Name,
+				app.Namespace,
+				cycle,
+			)
+		}
+
+		// The status of paused apps does not change so alerts should exclude
+		// them with e.g. `unless on(name, namespace) app_operator_app_paused`.
+		if expkey.IsAppPaused(app) {
+			ch <- prometheus.MustNewConstMetric(
+				appPausedDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				app.Name,
+				app.Namespace,
+				team,
+			)
+		}
+
 		if !key.IsAppCordoned(app) {
 			continue
 		}
 
-		t, err := convertToTime(key.CordonUntil(app))
+		ch <- prometheus.MustNewConstMetric(
+			appCordonInfoDesc,
+			prometheus.GaugeValue,
+			gaugeValue,
+			app.Name,
+			app.Namespace,
+			formatCordonReason(expkey.CordonReason(app)),
+		)
+
+		t, err := convertToTime(expkey.CordonUntil(app))
 		if err != nil {
 			a.logger.Errorf(ctx, err, "could not convert cordon-until for app %q", key.AppName(app))
+			err = c.update(func() {
+				a.cordonUntilParseErrors.inc(app)
+			})
+			if err != nil {
+				return microerror.Mask(err)
+			}
 			continue
 		}
 
@@ -228,48 +817,238 @@ func (a *App) collectAppStatus(ctx context.Context, ch chan<- prometheus.Metric)
 			appCordonExpireTimeDesc,
 			prometheus.GaugeValue,
 			float64(t.Unix()),
-			key.AppName(app),
-			key.Namespace(app),
+			app.Name,
+			app.Namespace,
+		)
+
+		// The annotations of expired cordons are not removed automatically.
+		// Forgotten cordons keep blocking upgrades without anyone noticing.
+		ch <- prometheus.MustNewConstMetric(
+			appCordonExpiredDesc,
+			prometheus.GaugeValue,
+			boolToFloat64(!t.After(now)),
+			app.Name,
+			app.Namespace,
 		)
+
+		err = c.update(func() {
+			a.events.cordonObserved(&app, expkey.CordonUntil(app), t, now)
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
 	}
-	return nil
-}
 
-// getLatestAppVersions checks for the latest version of each app in public catalogs.
-// There will be an AppCatalogEntry CR with the label latest=true for the latest
-// entry according to semantic versioning.
-func (a *App) getLatestAppVersions(ctx context.Context) (map[string]string, error) {
-	latestAppVersions := map[string]string{}
+	if configsFailed {
+		recordError(collectorApp, stageConfigs)
+		degraded = true
+	}
 
-	// TODO: Remove community once helm-stable catalog is removed.
-	// https://github.com/giantswarm/giantswarm/issues/17490
-	catalogLabels, err := labels.Parse(fmt.Sprintf("%s=public,%s!=community", label.CatalogVisibility, label.CatalogType))
-	if err != nil {
-		return nil, microerror.Mask(err)
+	if catalogEntriesFailed {
+		recordError(collectorApp, stageCatalogEntries)
+		degraded = true
 	}
 
-	catalogs := &v1alpha1.CatalogList{}
-	err = a.k8sClient.CtrlClient().List(ctx, catalogs, &client.ListOptions{LabelSelector: catalogLabels})
+	var cordonUntilParseErrors map[types.NamespacedName]int
+	err = c.update(func() {
+		a.statuses.prune(apps)
+		a.events.prune(apps)
+		a.cordonUntilParseErrors.prune(apps)
+		cordonUntilParseErrors = a.cordonUntilParseErrors.list()
+	})
 	if err != nil {
-		return nil, microerror.Mask(err)
+		return microerror.Mask(err)
 	}
 
-	for _, catalog := range catalogs.Items {
+	// Counts of deleted App CRs are pruned above, so their series go away.
+	for n, count := range cordonUntilParseErrors {
+		ch <- prometheus.MustNewConstMetric(
+			appCordonUntilParseErrorsDesc,
+			prometheus.CounterValue,
+			float64(count),
+			n.Name,
+			n.Namespace,
+		)
+	}
+
+	if !degraded {
+		recordSuccess(collectorApp)
+	}
+
+	return nil
+}
+
+// getCatalogAppVersions returns the versions of each app in the given catalogs
+// which are selected, see selectCatalog. There will be an AppCatalogEntry CR with the
+// label latest=true for the latest entry according to semantic versioning.
+// All released versions are collected as well to count how far apps are
+// behind. When listing the entries of a catalog fails the other catalogs are
+// still checked and the versions found are returned together with the error.
+func (a *App) getCatalogAppVersions(ctx context.Context, catalogs []v1alpha1.Catalog) (map[catalogAppKey]appVersions, error) {
+	catalogAppVersions := map[catalogAppKey]appVersions{}
+	var errs []error
+
+	for _, catalog := range catalogs {
+		if !a.selectCatalog(catalog) {
+			continue
+		}
+
 		aces := &v1alpha1.AppCatalogEntryList{}
-		err = a.k8sClient.CtrlClient().List(ctx, aces, client.InNamespace(catalog.Namespace), client.MatchingLabels{
+		err := a.reader.List(ctx, aces, client.InNamespace(catalog.Namespace), client.MatchingLabels{
 			label.CatalogName: catalog.Name,
-			"latest":          "true",
 		})
 		if err != nil {
-			return nil, microerror.Mask(err)
+			errs = append(errs, err)
+			continue
 		}
 
 		for _, ace := range aces.Items {
-			latestAppVersions[fmt.Sprintf("%s-%s", ace.Spec.Catalog.Name, ace.Spec.AppName)] = expkey.FormatVersion(ace.Spec.Version)
+			k := catalogAppKey{
+				namespace: catalog.Namespace,
+				catalog:   catalog.Name,
+				app:       ace.Spec.AppName,
+			}
+			version := expkey.FormatVersion(ace.Spec.Version)
+
+			v := catalogAppVersions[k]
+			if ace.Labels["latest"] == "true" {
+				v.latest = version
+			}
+			if sv, err := semver.NewVersion(version); err == nil && sv.Prerelease() == "" {
+				v.released = append(v.released, sv)
+			}
+			catalogAppVersions[k] = v
 		}
 	}
 
-	return latestAppVersions, nil
+	return catalogAppVersions, microerror.Mask(errors.Join(errs...))
+}
+
+// listCatalogs returns all catalogs in the watched namespaces. All catalogs
+// are listed as allowlisted catalogs may not match the label selector and
+// App CRs may reference any catalog. They are served from the cache anyway.
+// When listing a namespace fails the catalogs found are returned together
+// with the error.
+func (a *App) listCatalogs(ctx context.Context) ([]v1alpha1.Catalog, error) {
+	var catalogs []v1alpha1.Catalog
+	var errs []error
+
+	for _, ns := range listNamespaces(a.namespaces) {
+		l := &v1alpha1.CatalogList{}
+		err := a.reader.List(ctx, l, client.InNamespace(ns))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		catalogs = append(catalogs, l.Items...)
+	}
+
+	return catalogs, microerror.Mask(errors.Join(errs...))
+}
+
+// getMissingConfigReferences returns the ConfigMaps and Secrets referenced by
+// the App CR which do not exist. Only their metadata is read so the content of
+// Secrets is never seen. References into namespaces which are not watched are
+// skipped as we cannot tell. When a lookup fails the other references are
+// still checked and the error is returned together with the missing ones.
+func (a *App) getMissingConfigReferences(ctx context.Context, app v1alpha1.App) ([]expkey.ConfigReference, error) {
+	var missing []expkey.ConfigReference
+	var errs []error
+
+	for _, ref := range expkey.ConfigReferences(app) {
+		if !isWatchedNamespace(a.namespaces, ref.Namespace) {
+			continue
+		}
+
+		exists, err := a.configExists(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+		} else if !exists {
+			missing = append(missing, ref)
+		}
+	}
+
+	return missing, microerror.Mask(errors.Join(errs...))
+}
+
+// isKubeConfigSecretMissing returns true if the App CR is installed to a
+// workload cluster and its kubeconfig secret is not configured or does not
+// exist. Only the metadata of the secret is read. known is false when this
+// cannot be told, i.e. for in-cluster apps and secrets in namespaces which
+// are not watched.
+func (a *App) isKubeConfigSecretMissing(ctx context.Context, app v1alpha1.App) (ref expkey.ConfigReference, missing bool, known bool, err error) {
+	ref, ok := expkey.KubeConfigReference(app)
+	if !ok {
+		return ref, false, false, nil
+	}
+	if ref.Name == "" || ref.Namespace == "" {
+		return ref, true, true, nil
+	}
+	if !isWatchedNamespace(a.namespaces, ref.Namespace) {
+		return ref, false, false, nil
+	}
+
+	exists, err := a.configExists(ctx, ref)
+	if err != nil {
+		return ref, false, false, microerror.Mask(err)
+	}
+
+	return ref, !exists, true, nil
+}
+
+// configExists returns true if the referenced ConfigMap or Secret exists. Only
+// its metadata is read.
+func (a *App) configExists(ctx context.Context, ref expkey.ConfigReference) (bool, error) {
+	err := a.reader.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cache.NewMetadata(ref.Kind))
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+// isAppCatalogEntryMissing returns true if there is no AppCatalogEntry CR for
+// the version of the App CR in the namespace of its catalog. Catalogs may
+// carry `v`-prefixed versions independent of the version in the App CR so
+// the entry is looked up with and without the prefix.
+func (a *App) isAppCatalogEntryMissing(ctx context.Context, app v1alpha1.App, catalog v1alpha1.Catalog) (bool, error) {
+	version := expkey.FormatVersion(app.Spec.Version)
+
+	for _, v := range []string{version, "v" + version} {
+		ace := &v1alpha1.AppCatalogEntry{}
+		err := a.reader.Get(ctx, types.NamespacedName{
+			Namespace: catalog.Namespace,
+			Name:      key.AppCatalogEntryName(catalog.Name, key.AppName(app), v),
+		}, ace)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// selectCatalog returns true if upgrades should be checked for apps of the
+// given catalog. Denylisted catalogs are never selected. Allowlisted catalogs
+// are always selected. Any other catalog is selected if it matches the
+// catalog label selector. List entries are either a catalog name or
+// namespace/name.
+func (a *App) selectCatalog(catalog v1alpha1.Catalog) bool {
+	if matchesCatalogList(a.catalogDenylist, catalog) {
+		return false
+	}
+	if matchesCatalogList(a.catalogAllowlist, catalog) {
+		return true
+	}
+
+	return a.catalogLabelSelector.Matches(labels.Set(catalog.Labels))
 }
 
 func (a *App) getOwningTeam(ctx context.Context, app v1alpha1.App, owners []owner) (string, error) {
@@ -287,19 +1066,20 @@ func (a *App) getOwningTeam(ctx context.Context, app v1alpha1.App, owners []owne
 	return "", nil
 }
 
-// getTeam returns the team to assign for this app CR. It checks the
-// AppCatalogEntry CR to see if it has owners or team annotations.
-func (a *App) getTeam(ctx context.Context, app v1alpha1.App) (string, error) {
-	var team string
+// getTeamCandidates returns the candidate teams for this App CR from the
+// configured app team mappings and from its AppCatalogEntry CR. Candidates are
+// only returned for sources which are set. When the AppCatalogEntry CR cannot
+// be read the candidates found so far are returned together with the error.
+func (a *App) getTeamCandidates(ctx context.Context, app v1alpha1.App, mappings *TeamMappings) ([]TeamCandidate, error) {
+	var candidates []TeamCandidate
 	var err error
 
 	// Team has been configured manually via the configmap. This can be used
 	// if the team annotation is missing in Chart.yaml. Make sure the
 	// annotation is added and once its present for all deployments of the
 	// app the mapping can be removed.
-	team = a.appTeamMappings[key.AppName(app)]
-	if team != "" {
-		return team, nil
+	if team := mappings.AppTeams[key.AppName(app)]; team != "" {
+		candidates = append(candidates, TeamCandidate{Source: TeamSourceAppTeamMapping, Team: team})
 	}
 
 	// Note, for custom catalogs carrying the `v`-prefixed app versions, constructing
@@ -316,12 +1096,16 @@ func (a *App) getTeam(ctx context.Context, app v1alpha1.App) (string, error) {
 		// Check giantswarm namespace first as it has more CRs.
 		namespaces := []string{"giantswarm", metav1.NamespaceDefault}
 		for _, ns := range namespaces {
-			err = a.k8sClient.CtrlClient().Get(ctx, types.NamespacedName{Namespace: ns, Name: appCatalogEntryName}, ace)
+			if !isWatchedNamespace(a.namespaces, ns) {
+				continue
+			}
+
+			err = a.reader.Get(ctx, types.NamespacedName{Namespace: ns, Name: appCatalogEntryName}, ace)
 			if apierrors.IsNotFound(err) {
 				// Check next namespace.
 				continue
 			} else if err != nil {
-				return "", microerror.Mask(err)
+				return candidates, microerror.Mask(err)
 			} else {
 				break
 			}
@@ -342,50 +1126,130 @@ func (a *App) getTeam(ctx context.Context, app v1alpha1.App) (string, error) {
 		}
 
 		if len(owners) > 0 {
-			team, err = a.getOwningTeam(ctx, app, owners)
+			team, err := a.getOwningTeam(ctx, app, owners)
 			if err != nil {
-				return "", microerror.Mask(err)
+				return candidates, microerror.Mask(err)
+			}
+
+			if team != "" {
+				// Normalize team name e.g. remove team- prefix if its present.
+				candidates = append(candidates, TeamCandidate{Source: TeamSourceACEOwners, Team: formatTeamName(team)})
 			}
 		}
 	}
 
-	// Use team annotation if there are no owners.
-	if team == "" {
-		team = key.AppCatalogEntryTeam(*ace)
+	if team := key.AppCatalogEntryTeam(*ace); team != "" {
+		candidates = append(candidates, TeamCandidate{Source: TeamSourceACETeam, Team: formatTeamName(team)})
 	}
 
-	// Normalize team name e.g. remove team- prefix if its present.
-	team = formatTeamName(team)
+	return candidates, nil
+}
+
+// getAppTeamCandidates returns the candidate teams set on the App CR itself.
+func getAppTeamCandidates(app v1alpha1.App) []TeamCandidate {
+	var candidates []TeamCandidate
 
-	// Team may have been retired. If so we try to map it to the new team.
-	newTeam := a.retiredTeamsMapping[team]
-	if newTeam != "" {
-		return newTeam, nil
+	if key.AppTeam(app) != "" {
+		candidates = append(candidates, TeamCandidate{Source: TeamSourceAppAnnotation, Team: formatTeamName(key.AppTeam(app))})
 	}
 
-	return team, nil
+	if v, ok := app.Labels[annotation.AppTeam]; ok {
+		candidates = append(candidates, TeamCandidate{Source: TeamSourceAppLabel, Team: formatTeamName(v)})
+	}
+
+	return candidates
 }
 
-// getTeamMappings returns a map of AppCatalogEntry CR names to teams. This
-// reduces the number of API calls we need to make to fetch the teams metadata.
-func (a *App) getTeamMappings(ctx context.Context, apps []v1alpha1.App) (map[string]string, error) {
-	teamMappings := map[string]string{}
+// getTeamMappings returns a map of AppCatalogEntry CR names to their candidate
+// teams. This reduces the number of API calls we need to make to fetch the
+// teams metadata. When the candidates of an app cannot be determined the ones
+// found so far are kept, and the error is returned together with the mappings
+// found.
+func (a *App) getTeamMappings(ctx context.Context, apps []v1alpha1.App, mappings *TeamMappings) (map[string][]TeamCandidate, error) {
+	teamMappings := map[string][]TeamCandidate{}
+	var errs []error
 
 	for _, app := range apps {
 		appCatalogEntryName := key.AppCatalogEntryName(key.CatalogName(app), key.AppName(app), key.Version(app))
 
 		_, ok := teamMappings[appCatalogEntryName]
 		if !ok {
-			team, err := a.getTeam(ctx, app)
+			candidates, err := a.getTeamCandidates(ctx, app, mappings)
 			if err != nil {
-				return nil, microerror.Mask(err)
+				errs = append(errs, err)
 			}
 
-			teamMappings[appCatalogEntryName] = team
+			teamMappings[appCatalogEntryName] = candidates
+		}
+	}
+
+	return teamMappings, microerror.Mask(errors.Join(errs...))
+}
+
+// catalogNamespaces returns the namespaces the Catalog CR referenced by the
+// App CR is searched in. Like app-operator the default and giantswarm
+// namespaces are searched when spec.catalogNamespace is empty.
+func catalogNamespaces(app v1alpha1.App) []string {
+	if key.CatalogNamespace(app) != "" {
+		return []string{key.CatalogNamespace(app)}
+	}
+
+	return []string{metav1.NamespaceDefault, "giantswarm"}
+}
+
+// catalogMissingReason returns why the Catalog CR referenced by the App CR
+// cannot be found or an empty string if it exists. The reason is empty as
+// well when one of the namespaces of the catalog is not watched as we cannot
+// tell then.
+func catalogMissingReason(catalogs []v1alpha1.Catalog, watchedNamespaces []string, app v1alpha1.App) string {
+	if _, ok := findCatalog(catalogs, app); ok {
+		return ""
+	}
+
+	for _, ns := range catalogNamespaces(app) {
+		if !isWatchedNamespace(watchedNamespaces, ns) {
+			return ""
 		}
 	}
 
-	return teamMappings, nil
+	for _, catalog := range catalogs {
+		if catalog.Name == key.CatalogName(app) {
+			return catalogMissingReasonWrongNamespace
+		}
+	}
+
+	return catalogMissingReasonNotFound
+}
+
+// findCatalog returns the Catalog CR referenced by the App CR.
+func findCatalog(catalogs []v1alpha1.Catalog, app v1alpha1.App) (v1alpha1.Catalog, bool) {
+	for _, ns := range catalogNamespaces(app) {
+		for _, catalog := range catalogs {
+			if catalog.Name == key.CatalogName(app) && catalog.Namespace == ns {
+				return catalog, true
+			}
+		}
+	}
+
+	return v1alpha1.Catalog{}, false
+}
+
+func matchesCatalogList(list []string, catalog v1alpha1.Catalog) bool {
+	for _, entry := range list {
+		if entry == catalog.Name || entry == fmt.Sprintf("%s/%s", catalog.Namespace, catalog.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
 }
 
 // appVersion returns the AppVersion if it differs from the Version. This is so
@@ -400,20 +1264,45 @@ func appVersion(app v1alpha1.App) string {
 	return ""
 }
 
+// cordonUntilLayouts are the accepted layouts of the cordon-until annotation.
+// The legacy layout has no timezone and is interpreted as UTC. Fractional
+// seconds are accepted by all of them.
+var cordonUntilLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+}
+
 func convertToTime(input string) (time.Time, error) {
-	layout := "2006-01-02T15:04:05"
+	input = strings.TrimSpace(input)
 
-	split := strings.Split(input, ".")
-	if len(split) == 0 {
-		return time.Time{}, microerror.Maskf(invalidExecutionError, "%#q must have at least one item in order to collect metrics for the cordon expiration", input)
+	var errs []error
+	for _, layout := range cordonUntilLayouts {
+		t, err := time.Parse(layout, input)
+		if err == nil {
+			return t, nil
+		}
+
+		errs = append(errs, err)
 	}
 
-	t, err := time.Parse(layout, split[0])
-	if err != nil {
-		return time.Time{}, microerror.Maskf(invalidExecutionError, "parsing timestamp %#q failed: %#v", split[0], err.Error())
+	return time.Time{}, microerror.Maskf(invalidExecutionError, "parsing timestamp %#q failed: %#v", input, errors.Join(errs...).Error())
+}
+
+// maxCordonReasonLength is the number of characters of the cordon reason
+// exposed as a label.
+const maxCordonReasonLength = 64
+
+// formatCordonReason collapses whitespace and truncates the free text cordon
+// reason so it can be exposed as a label. There is at most one series per
+// cordoned App CR.
+func formatCordonReason(input string) string {
+	reason := []rune(strings.Join(strings.Fields(input), " "))
+	if len(reason) > maxCordonReasonLength {
+		reason = reason[:maxCordonReasonLength]
 	}
 
-	return t, nil
+	return string(reason)
 }
 
 // formatTeamName allows for normalizing the team name. This is needed as our
@@ -2,18 +2,20 @@ package collector
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
-	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	"github.com/giantswarm/app/v7/pkg/key"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/app-exporter/pkg/project"
 )
@@ -34,12 +36,25 @@ var (
 type AppOperatorConfig struct {
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
+	// Reader is used for all List calls. When empty the controller-runtime
+	// client of K8sClient is used.
+	Reader client.Reader
+
+	LabelSelector labels.Selector
+	Namespaces    []string
+	Timeout       time.Duration
 }
 
 // AppOperator is the main struct for this collector.
 type AppOperator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+	reader    client.Reader
+
+	labelSelector labels.Selector
+	namespaces    []string
+	snapshot      snapshot
+	timeout       time.Duration
 }
 
 // NewAppOperator creates a new AppOperator metrics collector
@@ -51,9 +66,19 @@ func NewAppOperator(config AppOperatorConfig) (*AppOperator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	reader := config.Reader
+	if reader == nil {
+		reader = config.K8sClient.CtrlClient()
+	}
+
 	a := &AppOperator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+		reader:    reader,
+
+		labelSelector: config.LabelSelector,
+		namespaces:    config.Namespaces,
+		timeout:       config.Timeout,
 	}
 
 	return a, nil
@@ -61,9 +86,7 @@ func NewAppOperator(config AppOperatorConfig) (*AppOperator, error) {
 
 // Collect is the main metrics collection function.
 func (a *AppOperator) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
-
-	err := a.collectAppOperatorStatus(ctx, ch)
+	err := collectWithTimeout(a.logger, collectorAppOperator, a.timeout, &a.snapshot, ch, a.collectAppOperatorStatus)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -77,16 +100,26 @@ func (a *AppOperator) Describe(ch chan<- *prometheus.Desc) error {
 	return nil
 }
 
-func (a *AppOperator) collectAppOperatorStatus(ctx context.Context, ch chan<- prometheus.Metric) error {
+func (a *AppOperator) collectAppOperatorStatus(ctx context.Context, _ *collection, ch chan<- prometheus.Metric) error {
 	var err error
 
+	// collectWithTimeout records collections which hit their deadline as
+	// timeouts already.
 	appVersions, err := a.collectAppVersions(ctx)
 	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			recordError(collectorAppOperator, stageListApps)
+		}
 		return microerror.Mask(err)
 	}
 
+	// Without the deployments every version would be reported as having no
+	// ready app-operator instances, so we fail instead of emitting those.
 	operatorVersions, err := a.collectOperatorVersions(ctx)
 	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			recordError(collectorAppOperator, stageDeployments)
+		}
 		return microerror.Mask(err)
 	}
 
@@ -115,6 +148,8 @@ func (a *AppOperator) collectAppOperatorStatus(ctx context.Context, ch chan<- pr
 		}
 	}
 
+	recordSuccess(collectorAppOperator)
+
 	return nil
 }
 
@@ -123,13 +158,12 @@ func (a *AppOperator) collectAppOperatorStatus(ctx context.Context, ch chan<- pr
 func (a *AppOperator) collectAppVersions(ctx context.Context) (map[string]map[string]bool, error) {
 	appVersions := map[string]map[string]bool{}
 
-	apps := &v1alpha1.AppList{}
-	err := a.k8sClient.CtrlClient().List(ctx, apps)
+	apps, err := listApps(ctx, a.reader, a.namespaces, a.labelSelector)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
-	for _, app := range apps.Items {
+	for _, app := range apps {
 		// Skip apps that are in `org-*` namespaces (CAPI, `app-operator.giantswarm.io/version` label is not mandatory there)
 		if key.IsInOrgNamespace(app) {
 			a.logger.Debugf(ctx, "Skipping collecting App versions in `org-*` namespaces for app %#q in %#q", app.Name, app.Namespace)
@@ -162,15 +196,20 @@ func (a *AppOperator) collectAppVersions(ctx context.Context) (map[string]map[st
 func (a *AppOperator) collectOperatorVersions(ctx context.Context) (map[string]map[string]int32, error) {
 	operatorVersions := map[string]map[string]int32{}
 
-	lo := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", label.App, project.OperatorName()),
-	}
-	d, err := a.k8sClient.K8sClient().AppsV1().Deployments("").List(ctx, lo)
-	if err != nil {
-		return nil, microerror.Mask(err)
+	var deployments []appsv1.Deployment
+	for _, ns := range listNamespaces(a.namespaces) {
+		l := &appsv1.DeploymentList{}
+		err := a.reader.List(ctx, l, client.InNamespace(ns), client.MatchingLabels{
+			label.App: project.OperatorName(),
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		deployments = append(deployments, l.Items...)
 	}
 
-	for _, deploy := range d.Items {
+	for _, deploy := range deployments {
 		namespace := deploy.Namespace
 		replicas := deploy.Status.ReadyReplicas
 		version := deploy.Labels[label.AppKubernetesVersion]
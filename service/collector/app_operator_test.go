@@ -122,6 +122,7 @@ func Test_collectAppVersions(t *testing.T) {
 			appOperator := &AppOperator{
 				k8sClient: k8sClientFake,
 				logger:    microloggertest.New(),
+				reader:    k8sClientFake.CtrlClient(),
 			}
 
 			appVersions, err := appOperator.collectAppVersions(context.TODO())
@@ -2,9 +2,11 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,10 +18,16 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/prometheus/client_golang/prometheus"
 	prometheustest "github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	expkey "github.com/giantswarm/app-exporter/internal/key"
 )
 
 // fakeCollector implements prometheus.Collector interface and
@@ -54,15 +62,35 @@ func Test_convertToTime(t *testing.T) {
 			expected: expectedTime,
 		},
 		{
-			name:         "case 2: parsing error since unknown ",
-			datetime:     "2019-12-31T23:59:59Z",
-			errorMatcher: IsInvalidExecution,
+			name:     "case 2: legacy timestamp without fractional seconds",
+			datetime: "2019-12-31T23:59:59",
+			expected: expectedTime,
+		},
+		{
+			name:     "case 3: RFC3339 timestamp",
+			datetime: "2019-12-31T23:59:59Z",
+			expected: expectedTime,
+		},
+		{
+			name:     "case 4: RFC3339 timestamp with offset",
+			datetime: "2020-01-01T01:59:59+02:00",
+			expected: expectedTime,
 		},
 		{
-			name:         "case 3: parsing error as wrong date",
+			name:     "case 5: RFC3339Nano timestamp",
+			datetime: "2019-12-31T23:59:59.123456789Z",
+			expected: expectedTime.Add(123456789 * time.Nanosecond),
+		},
+		{
+			name:         "case 6: parsing error as wrong date",
 			datetime:     "2019-13-31T23:59:59Z",
 			errorMatcher: IsInvalidExecution,
 		},
+		{
+			name:         "case 7: parsing error as no timestamp",
+			datetime:     "tomorrow",
+			errorMatcher: IsInvalidExecution,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -76,7 +104,7 @@ func Test_convertToTime(t *testing.T) {
 				t.Fatalf("error == %#v, want matching", err)
 			}
 
-			if !reflect.DeepEqual(got, tc.expected) {
+			if !got.Equal(tc.expected) {
 				t.Errorf("convertToTime() = %v, want %v", got, tc.expected)
 			}
 		})
@@ -89,6 +117,8 @@ func Test_collectAppStatus(t *testing.T) {
 		apps                 []*v1alpha1.App
 		catalogs             []*v1alpha1.Catalog
 		catalogsEntries      []*v1alpha1.AppCatalogEntry
+		labelSelector        string
+		namespaces           []string
 		expectedMetrics      string
 		expectedMetricsCount int
 	}{
@@ -188,6 +218,74 @@ func Test_collectAppStatus(t *testing.T) {
 			expectedMetrics:      "testdata/expected.3",
 			expectedMetricsCount: 2,
 		},
+		{
+			name: "namespace scoped",
+			apps: []*v1alpha1.App{
+				newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil),
+				newApp("example", "customer", "default", "1.0.0", "", "", nil, nil),
+				newApp("test-app", "default", "test-app", "1.0.0", "", "", nil, map[string]string{
+					label.Cluster: "foo",
+				}),
+			},
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("giantswarm", "default"),
+				newCatalog("customer", "default"),
+				newCatalog("default", "giantswarm"),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+				newACE("example", "customer", "default", "1.0.0", "", "", true),
+				newACE("test-app", "default", "giantswarm", "1.0.0", "", "", true),
+			},
+			namespaces:           []string{"default", "hello-world"},
+			expectedMetrics:      "testdata/expected.4",
+			expectedMetricsCount: 2,
+		},
+		{
+			name: "label selector",
+			apps: []*v1alpha1.App{
+				newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil),
+				newApp("example", "customer", "default", "1.0.0", "", "", nil, nil),
+				newApp("test-app", "default", "test-app", "1.0.0", "", "", nil, map[string]string{
+					label.Cluster: "foo",
+				}),
+			},
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("giantswarm", "default"),
+				newCatalog("customer", "default"),
+				newCatalog("default", "giantswarm"),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+				newACE("example", "customer", "default", "1.0.0", "", "", true),
+				newACE("test-app", "default", "giantswarm", "1.0.0", "", "", true),
+			},
+			labelSelector:        fmt.Sprintf("%s=foo", label.Cluster),
+			expectedMetrics:      "testdata/expected.5",
+			expectedMetricsCount: 1,
+		},
+		{
+			name: "catalogs with the same name in different namespaces",
+			apps: []*v1alpha1.App{
+				withCatalogNamespace(newApp("example", "internal", "org-acme", "1.0.0", "", "", nil, map[string]string{
+					label.Cluster: "acme",
+				}), "org-acme"),
+				withCatalogNamespace(newApp("example", "internal", "org-globex", "1.0.0", "", "", nil, map[string]string{
+					label.Cluster: "globex",
+				}), "org-globex"),
+			},
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("internal", "org-acme"),
+				newCatalog("internal", "org-globex"),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("example", "internal", "org-acme", "1.0.0", "", "", true),
+				newACE("example", "internal", "org-globex", "2.0.0", "", "", true),
+				newACE("example", "internal", "org-globex", "1.0.0", "", "", false),
+			},
+			expectedMetrics:      "testdata/expected.7",
+			expectedMetricsCount: 2,
+		},
 	}
 	for i, tc := range tests {
 		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
@@ -225,11 +323,18 @@ func Test_collectAppStatus(t *testing.T) {
 				})
 			}
 
+			labelSelector, err := labels.Parse(tc.labelSelector)
+			if err != nil {
+				t.Fatal(err)
+			}
+
 			appConfig := AppConfig{
 				K8sClient: k8sClientFake,
 				Logger:    microloggertest.New(),
 
 				DefaultTeam:         "honeybadger",
+				LabelSelector:       labelSelector,
+				Namespaces:          tc.namespaces,
 				Provider:            "aws",
 				RetiredTeamsMapping: map[string]string{},
 			}
@@ -268,12 +373,558 @@ func Test_collectAppStatus(t *testing.T) {
 	}
 }
 
-func Test_getLatestAppVersions(t *testing.T) {
+func Test_collectAppStatusDegraded(t *testing.T) {
+	var err error
+
+	gsObj := []runtime.Object{
+		newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil),
+		newApp("atlas-app", "giantswarm", "default", "0.9.0", "", "", map[string]string{annotation.AppTeam: "team-atlas"}, nil),
+		newCatalog("giantswarm", "default"),
+		newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+	}
+
+	// Fail every AppCatalogEntry lookup so neither latest versions nor teams
+	// can be determined.
+	failingACEs := interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*v1alpha1.AppCatalogEntry); ok {
+				return errors.New("injected error")
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if _, ok := list.(*v1alpha1.AppCatalogEntryList); ok {
+				return errors.New("injected error")
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}
+
+	k8sClientFake := newFakeClients(t, failingACEs, gsObj...)
+
+	app := newFakeApp(t, AppConfig{K8sClient: k8sClientFake})
+
+	latestVersionsErrors := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageLatestVersions))
+	teamsErrors := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageTeams))
+
+	expected, err := os.Open("testdata/expected.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = expected.Close() }()
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		expected,
+		prometheus.BuildFQName(namespace, "app", "info"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	if got := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageLatestVersions)); got != latestVersionsErrors+1 {
+		t.Errorf("expected %s errors to be %v, got %v", stageLatestVersions, latestVersionsErrors+1, got)
+	}
+	if got := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageTeams)); got != teamsErrors+1 {
+		t.Errorf("expected %s errors to be %v, got %v", stageTeams, teamsErrors+1, got)
+	}
+}
+
+func Test_collectAppStatusDeadline(t *testing.T) {
+	// Listing the App CRs hits the deadline of the collection.
+	deadline := interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if _, ok := list.(*v1alpha1.AppList); ok {
+				return context.DeadlineExceeded
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}
+
+	k8sClientFake := newFakeClients(t, deadline, newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil))
+
+	app := newFakeApp(t, AppConfig{K8sClient: k8sClientFake})
+
+	listAppsErrors := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageListApps))
+	timeoutErrors := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageTimeout))
+
+	num := prometheustest.CollectAndCount(
+		fakeCollector{app: app},
+		prometheus.BuildFQName(namespace, "app", "info"),
+	)
+	if num != 0 {
+		t.Errorf("expected 0 metrics to collect, got %d", num)
+	}
+
+	// The deadline is only recorded as timeout and not as error of the
+	// stage which noticed it.
+	if got := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageListApps)); got != listAppsErrors {
+		t.Errorf("expected %s errors to be %v, got %v", stageListApps, listAppsErrors, got)
+	}
+	if got := prometheustest.ToFloat64(collectorErrorsTotal.WithLabelValues(collectorApp, stageTimeout)); got != timeoutErrors+1 {
+		t.Errorf("expected %s errors to be %v, got %v", stageTimeout, timeoutErrors+1, got)
+	}
+}
+
+func Test_collectAppStatusCordon(t *testing.T) {
+	var err error
+
+	invalid := newApp("invalid-app", "giantswarm", "default", "1.0.0", "", "", map[string]string{
+		annotation.AppOperatorCordonReason: "testing",
+		annotation.AppOperatorCordonUntil:  "tomorrow",
+	}, nil)
+
+	gsObj := []runtime.Object{
+		newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", map[string]string{
+			annotation.AppOperatorCordonReason: "Waiting for the\n  customer to approve the upgrade during their next maintenance window",
+			annotation.AppOperatorCordonUntil:  "2024-01-01T16:00:00+02:00",
+		}, nil),
+		newApp("legacy-app", "giantswarm", "default", "1.0.0", "", "", map[string]string{
+			annotation.AppOperatorCordonReason: "testing",
+			annotation.AppOperatorCordonUntil:  "2024-01-01T12:00:00.000",
+		}, nil),
+		invalid,
+	}
+
+	// Apps of different clusters install the same app into the same target
+	// namespace. They are told apart by the name of their App CR.
+	for _, name := range []string{"abc12-cert-manager", "xyz34-cert-manager"} {
+		app := newApp(name, "giantswarm", "org-acme", "3.0.0", "", "", map[string]string{
+			annotation.AppOperatorCordonReason: "testing",
+			annotation.AppOperatorCordonUntil:  "2024-01-01T14:00:00Z",
+		}, nil)
+		app.Spec.Name = "cert-manager"
+		app.Spec.Namespace = "kube-system"
+
+		gsObj = append(gsObj, app)
+	}
+
+	k8sClientFake := newFakeClients(t, interceptor.Funcs{}, gsObj...)
+
+	app := newFakeApp(t, AppConfig{K8sClient: k8sClientFake})
+
+	app.now = func() time.Time { return time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC) }
+
+	expected := `
+# HELP app_operator_app_cordon_expire_time_seconds A metric of the expire time of cordoned apps unix seconds.
+# TYPE app_operator_app_cordon_expire_time_seconds gauge
+app_operator_app_cordon_expire_time_seconds{name="abc12-cert-manager",namespace="org-acme"} 1.7041176e+09
+app_operator_app_cordon_expire_time_seconds{name="hello-world-app",namespace="hello-world"} 1.7041176e+09
+app_operator_app_cordon_expire_time_seconds{name="legacy-app",namespace="default"} 1.7041104e+09
+app_operator_app_cordon_expire_time_seconds{name="xyz34-cert-manager",namespace="org-acme"} 1.7041176e+09
+# HELP app_operator_app_cordon_expired Whether the cordon of the app has expired but its annotations were not removed.
+# TYPE app_operator_app_cordon_expired gauge
+app_operator_app_cordon_expired{name="abc12-cert-manager",namespace="org-acme"} 0
+app_operator_app_cordon_expired{name="hello-world-app",namespace="hello-world"} 0
+app_operator_app_cordon_expired{name="legacy-app",namespace="default"} 1
+app_operator_app_cordon_expired{name="xyz34-cert-manager",namespace="org-acme"} 0
+# HELP app_operator_app_cordon_info Cordoned apps with the reason of the cordon truncated to 64 characters.
+# TYPE app_operator_app_cordon_info gauge
+app_operator_app_cordon_info{name="abc12-cert-manager",namespace="org-acme",reason="testing"} 1
+app_operator_app_cordon_info{name="hello-world-app",namespace="hello-world",reason="Waiting for the customer to approve the upgrade during their nex"} 1
+app_operator_app_cordon_info{name="invalid-app",namespace="default",reason="testing"} 1
+app_operator_app_cordon_info{name="legacy-app",namespace="default",reason="testing"} 1
+app_operator_app_cordon_info{name="xyz34-cert-manager",namespace="org-acme",reason="testing"} 1
+# HELP app_operator_app_cordon_until_parse_errors_total Number of collections in which the cordon-until annotation of the app could not be parsed.
+# TYPE app_operator_app_cordon_until_parse_errors_total counter
+app_operator_app_cordon_until_parse_errors_total{name="invalid-app",namespace="default"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "cordon_expire_time_seconds"),
+		prometheus.BuildFQName(namespace, "app", "cordon_expired"),
+		prometheus.BuildFQName(namespace, "app", "cordon_info"),
+		prometheus.BuildFQName(namespace, "app", "cordon_until_parse_errors_total"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	// Every collection counts the parse error until the App CR is deleted.
+	expected = `
+# HELP app_operator_app_cordon_until_parse_errors_total Number of collections in which the cordon-until annotation of the app could not be parsed.
+# TYPE app_operator_app_cordon_until_parse_errors_total counter
+app_operator_app_cordon_until_parse_errors_total{name="invalid-app",namespace="default"} 2
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "cordon_until_parse_errors_total"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	err = k8sClientFake.CtrlClient().Delete(context.Background(), invalid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	num := prometheustest.CollectAndCount(
+		fakeCollector{app: app},
+		prometheus.BuildFQName(namespace, "app", "cordon_until_parse_errors_total"),
+	)
+	if num != 0 {
+		t.Errorf("expected 0 metrics to collect, got %d", num)
+	}
+}
+
+func Test_collectAppStatusPaused(t *testing.T) {
+	var err error
+
+	gsObj := []runtime.Object{
+		newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", map[string]string{
+			annotation.AppOperatorPaused: "true",
+			annotation.AppTeam:           "team-atlas",
+		}, nil),
+		newApp("example", "customer", "default", "1.0.0", "", "", nil, nil),
+	}
+
+	app := newFakeApp(t, AppConfig{}, gsObj...)
+
+	expected := `
+# HELP app_operator_app_paused Apps which are not reconciled by app-operator because they are paused.
+# TYPE app_operator_app_paused gauge
+app_operator_app_paused{name="hello-world-app",namespace="hello-world",team="atlas"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "paused"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+}
+
+func Test_collectAppStatusDependencies(t *testing.T) {
+	var err error
+
+	newClusterApp := func(name, appName, status, dependsOn, cluster string) *v1alpha1.App {
+		app := newApp(name, "default", "org-acme", "1.0.0", "", status, map[string]string{
+			expkey.AnnotationDependsOn: dependsOn,
+		}, map[string]string{
+			label.Cluster: cluster,
+		})
+		app.Spec.Name = appName
+
+		return app
+	}
+
+	// Dependencies resolve to the App CR name or spec.name of apps in the
+	// same cluster only. kyverno is installed to another cluster.
+	gsObj := []runtime.Object{
+		newClusterApp("abc12-cert-manager", "cert-manager", "deployed", "", "abc12"),
+		newClusterApp("abc12-ingress", "ingress", "failed", "", "abc12"),
+		newClusterApp("abc12-external-dns", "external-dns", "not-installed", "cert-manager, abc12-ingress", "abc12"),
+		newClusterApp("abc12-policies", "policies", "not-installed", "kyverno", "abc12"),
+		newClusterApp("abc12-first", "first", "not-installed", "abc12-second", "abc12"),
+		newClusterApp("abc12-second", "second", "not-installed", "abc12-first", "abc12"),
+		newClusterApp("xyz34-kyverno", "kyverno", "deployed", "", "xyz34"),
+		newClusterApp("xyz34-self", "self", "not-installed", "self", "xyz34"),
+	}
+
+	app := newFakeApp(t, AppConfig{}, gsObj...)
+
+	expected := `
+# HELP app_operator_app_dependency_cycle Apps which are part of a dependency cycle with the sorted names of all apps in the cycle.
+# TYPE app_operator_app_dependency_cycle gauge
+app_operator_app_dependency_cycle{cycle="abc12-first,abc12-second",name="abc12-first",namespace="org-acme"} 1
+app_operator_app_dependency_cycle{cycle="abc12-first,abc12-second",name="abc12-second",namespace="org-acme"} 1
+app_operator_app_dependency_cycle{cycle="xyz34-self",name="xyz34-self",namespace="org-acme"} 1
+# HELP app_operator_app_dependency_unmet Dependencies of apps which are missing or not deployed.
+# TYPE app_operator_app_dependency_unmet gauge
+app_operator_app_dependency_unmet{dependency="abc12-first",name="abc12-second",namespace="org-acme"} 1
+app_operator_app_dependency_unmet{dependency="abc12-ingress",name="abc12-external-dns",namespace="org-acme"} 1
+app_operator_app_dependency_unmet{dependency="abc12-second",name="abc12-first",namespace="org-acme"} 1
+app_operator_app_dependency_unmet{dependency="kyverno",name="abc12-policies",namespace="org-acme"} 1
+app_operator_app_dependency_unmet{dependency="self",name="xyz34-self",namespace="org-acme"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "dependency_cycle"),
+		prometheus.BuildFQName(namespace, "app", "dependency_unmet"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+}
+
+func Test_collectAppStatusCatalogReferences(t *testing.T) {
+	var err error
+
+	typo := newApp("typo-app", "giantswarm", "default", "0.3.1", "", "", nil, nil)
+	controlPlane := newApp("control-plane-app", "control-plane", "default", "1.0.0", "", "", nil, nil)
+	controlPlane.Spec.CatalogNamespace = "giantswarm"
+	misplaced := newApp("misplaced-app", "control-plane", "default", "1.0.0", "", "", nil, nil)
+	misplaced.Spec.CatalogNamespace = "giantswarm"
+	wrongNamespace := newApp("wrong-namespace-app", "control-plane", "default", "1.0.0", "", "", nil, nil)
+	wrongNamespace.Spec.CatalogNamespace = "default"
+
+	gsObj := []runtime.Object{
+		newCatalog("giantswarm", "default"),
+		newCatalog("customer", "default"),
+		newCatalog("control-plane", "giantswarm"),
+		newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+		newACE("typo-app", "giantswarm", "default", "0.3.0", "", "", true),
+		newACE("example", "customer", "default", "v1.0.0", "", "", true),
+		newACE("control-plane-app", "control-plane", "giantswarm", "1.0.0", "", "", true),
+		newACE("misplaced-app", "control-plane", "default", "1.0.0", "", "", true),
+		newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil),
+		newApp("example", "customer", "default", "1.0.0", "", "", nil, nil),
+		newApp("missing-catalog-app", "missing", "default", "1.0.0", "", "", nil, nil),
+		typo,
+		controlPlane,
+		misplaced,
+		wrongNamespace,
+	}
+
+	app := newFakeApp(t, AppConfig{}, gsObj...)
+
+	// The ACE of misplaced-app is only in the default namespace but its
+	// catalog is in the giantswarm namespace. Entries of apps referencing a
+	// missing catalog are not checked.
+	expected := `
+# HELP app_operator_app_catalog_missing Apps referencing a catalog which does not exist in the catalog namespace of the app.
+# TYPE app_operator_app_catalog_missing gauge
+app_operator_app_catalog_missing{catalog="control-plane",catalog_namespace="default",name="wrong-namespace-app",namespace="default",reason="wrong-namespace"} 1
+app_operator_app_catalog_missing{catalog="missing",catalog_namespace="",name="missing-catalog-app",namespace="default",reason="not-found"} 1
+# HELP app_operator_app_catalog_entry_missing Apps whose version has no AppCatalogEntry CR in their catalog.
+# TYPE app_operator_app_catalog_entry_missing gauge
+app_operator_app_catalog_entry_missing{catalog="control-plane",name="misplaced-app",namespace="default",version="1.0.0"} 1
+app_operator_app_catalog_entry_missing{catalog="giantswarm",name="typo-app",namespace="default",version="0.3.1"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "catalog_entry_missing"),
+		prometheus.BuildFQName(namespace, "app", "catalog_missing"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+}
+
+func Test_collectAppStatusConfigReferences(t *testing.T) {
+	var err error
+
+	app := newApp("hello-world-app", "giantswarm", "org-acme", "0.3.0", "", "", nil, nil)
+	app.Spec.Config.ConfigMap = v1alpha1.AppSpecConfigConfigMap{Name: "cluster-values", Namespace: "org-acme"}
+	app.Spec.Config.Secret = v1alpha1.AppSpecConfigSecret{Name: "cluster-secrets", Namespace: "org-acme"}
+	app.Spec.UserConfig.ConfigMap = v1alpha1.AppSpecUserConfigConfigMap{Name: "hello-world-user-values", Namespace: "org-acme"}
+	app.Spec.UserConfig.Secret = v1alpha1.AppSpecUserConfigSecret{Name: "hello-world-user-secrets", Namespace: "org-acme"}
+	app.Spec.ExtraConfigs = []v1alpha1.AppExtraConfig{
+		{Name: "shared-values", Namespace: "giantswarm"},
+		{Kind: "secret", Name: "shared-secrets", Namespace: "giantswarm"},
+		{Kind: "secret", Name: "unwatched-secrets", Namespace: "unwatched"},
+	}
+
+	inCluster := newApp("in-cluster-app", "giantswarm", "giantswarm", "0.3.0", "", "", nil, nil)
+	inCluster.Spec.KubeConfig.InCluster = true
+
+	deleted := newApp("deleted-cluster-app", "giantswarm", "org-acme", "0.3.0", "", "", nil, map[string]string{
+		label.Cluster: "deleted",
+	})
+	deleted.Spec.KubeConfig.Secret = v1alpha1.AppSpecKubeConfigSecret{Name: "deleted-kubeconfig", Namespace: "org-acme"}
+
+	workload := newApp("workload-cluster-app", "giantswarm", "org-acme", "0.3.0", "", "", nil, map[string]string{
+		label.Cluster: "acme",
+	})
+	workload.Spec.KubeConfig.Secret = v1alpha1.AppSpecKubeConfigSecret{Name: "acme-kubeconfig", Namespace: "org-acme"}
+
+	gsObj := []runtime.Object{
+		app,
+		inCluster,
+		deleted,
+		workload,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "acme-kubeconfig", Namespace: "org-acme"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-values", Namespace: "org-acme"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cluster-secrets", Namespace: "org-acme"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared-values", Namespace: "giantswarm"}},
+		// A ConfigMap with the name of a referenced Secret does not count.
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared-secrets", Namespace: "giantswarm"}},
+	}
+
+	appConfig := AppConfig{
+		ConfigReferences:  true,
+		KubeConfigSecrets: true,
+		Namespaces:        []string{"giantswarm", "org-acme"},
+	}
+
+	a := newFakeApp(t, appConfig, gsObj...)
+
+	// The reference into the unwatched namespace is skipped. The first app
+	// is neither in-cluster nor has a kubeconfig secret configured.
+	expectedConfigReferences := `
+# HELP app_operator_app_config_reference_missing ConfigMaps and Secrets referenced by apps which do not exist.
+# TYPE app_operator_app_config_reference_missing gauge
+app_operator_app_config_reference_missing{kind="ConfigMap",name="hello-world-app",namespace="org-acme",ref_name="hello-world-user-values",ref_namespace="org-acme"} 1
+app_operator_app_config_reference_missing{kind="Secret",name="hello-world-app",namespace="org-acme",ref_name="hello-world-user-secrets",ref_namespace="org-acme"} 1
+app_operator_app_config_reference_missing{kind="Secret",name="hello-world-app",namespace="org-acme",ref_name="shared-secrets",ref_namespace="giantswarm"} 1
+`
+	expectedKubeConfigSecrets := `
+# HELP app_operator_app_kubeconfig_secret_missing Whether the kubeconfig secret of apps installed to a workload cluster is missing.
+# TYPE app_operator_app_kubeconfig_secret_missing gauge
+app_operator_app_kubeconfig_secret_missing{cluster_id="",cluster_missing="true",name="hello-world-app",namespace="org-acme",ref_name="",ref_namespace=""} 1
+app_operator_app_kubeconfig_secret_missing{cluster_id="acme",cluster_missing="false",name="workload-cluster-app",namespace="org-acme",ref_name="acme-kubeconfig",ref_namespace="org-acme"} 0
+app_operator_app_kubeconfig_secret_missing{cluster_id="deleted",cluster_missing="false",name="deleted-cluster-app",namespace="org-acme",ref_name="deleted-kubeconfig",ref_namespace="org-acme"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: a},
+		strings.NewReader(expectedConfigReferences+expectedKubeConfigSecrets),
+		prometheus.BuildFQName(namespace, "app", "config_reference_missing"),
+		prometheus.BuildFQName(namespace, "app", "kubeconfig_secret_missing"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	// The kubeconfig secret check only requires access to Secrets, so it
+	// is enabled on its own.
+	appConfig.ConfigReferences = false
+
+	a = newFakeApp(t, appConfig, gsObj...)
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: a},
+		strings.NewReader(expectedKubeConfigSecrets),
+		prometheus.BuildFQName(namespace, "app", "config_reference_missing"),
+		prometheus.BuildFQName(namespace, "app", "kubeconfig_secret_missing"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	appConfig.ConfigReferences = true
+	appConfig.KubeConfigSecrets = false
+
+	a = newFakeApp(t, appConfig, gsObj...)
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: a},
+		strings.NewReader(expectedConfigReferences),
+		prometheus.BuildFQName(namespace, "app", "config_reference_missing"),
+		prometheus.BuildFQName(namespace, "app", "kubeconfig_secret_missing"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	// The checks are opt-in as they require access to all ConfigMaps and
+	// Secrets.
+	appConfig.ConfigReferences = false
+
+	a = newFakeApp(t, appConfig, gsObj...)
+
+	num := prometheustest.CollectAndCount(
+		fakeCollector{app: a},
+		prometheus.BuildFQName(namespace, "app", "config_reference_missing"),
+		prometheus.BuildFQName(namespace, "app", "kubeconfig_secret_missing"),
+	)
+	if num != 0 {
+		t.Errorf("expected 0 metrics to collect, got %d", num)
+	}
+}
+
+func Test_collectAppStatusRelease(t *testing.T) {
+	var err error
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	app := newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "failed", nil, nil)
+	app.Status.Release.LastDeployed = metav1.NewTime(now.Add(-45 * time.Minute))
+	app.Status.Release.Reason = "timed out waiting for the condition"
+
+	k8sClientFake := newFakeClients(t, interceptor.Funcs{}, app, newApp("example", "customer", "default", "1.0.0", "", "pending-install", nil, nil))
+
+	a := newFakeApp(t, AppConfig{K8sClient: k8sClientFake})
+	a.now = func() time.Time { return now }
+
+	expected := `
+# HELP app_operator_app_failure_reason Class of the reason why the release of the app is not deployed.
+# TYPE app_operator_app_failure_reason gauge
+app_operator_app_failure_reason{name="hello-world-app",namespace="hello-world",reason_class="timeout"} 1
+# HELP app_operator_app_last_deployed_timestamp_seconds Time the release of the app was last deployed in unix seconds.
+# TYPE app_operator_app_last_deployed_timestamp_seconds gauge
+app_operator_app_last_deployed_timestamp_seconds{name="hello-world-app",namespace="hello-world"} 1.7041077e+09
+# HELP app_operator_app_release_status_age_seconds Seconds since the app is in its current release status.
+# TYPE app_operator_app_release_status_age_seconds gauge
+app_operator_app_release_status_age_seconds{name="example",namespace="default",status="pending-install"} 0
+app_operator_app_release_status_age_seconds{name="hello-world-app",namespace="hello-world",status="failed"} 2700
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: a},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "failure_reason"),
+		prometheus.BuildFQName(namespace, "app", "last_deployed_timestamp_seconds"),
+		prometheus.BuildFQName(namespace, "app", "release_status_age_seconds"),
+		prometheus.BuildFQName(namespace, "app", "status_last_transition_timestamp_seconds"),
+		prometheus.BuildFQName(namespace, "app", "status_transitions_total"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	// Ten minutes later the still pending app has aged while the failed app
+	// got deployed in the meantime. Its stale failure reason is not exposed
+	// anymore.
+	now = now.Add(10 * time.Minute)
+
+	app.Status.Release.Status = "deployed"
+	app.Status.Release.LastDeployed = metav1.NewTime(now.Add(-time.Minute))
+	err = k8sClientFake.CtrlClient().Update(context.Background(), app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `
+# HELP app_operator_app_last_deployed_timestamp_seconds Time the release of the app was last deployed in unix seconds.
+# TYPE app_operator_app_last_deployed_timestamp_seconds gauge
+app_operator_app_last_deployed_timestamp_seconds{name="hello-world-app",namespace="hello-world"} 1.70411094e+09
+# HELP app_operator_app_release_status_age_seconds Seconds since the app is in its current release status.
+# TYPE app_operator_app_release_status_age_seconds gauge
+app_operator_app_release_status_age_seconds{name="example",namespace="default",status="pending-install"} 600
+app_operator_app_release_status_age_seconds{name="hello-world-app",namespace="hello-world",status="deployed"} 0
+# HELP app_operator_app_status_last_transition_timestamp_seconds Time of the last observed change of the release status of the app in unix seconds.
+# TYPE app_operator_app_status_last_transition_timestamp_seconds gauge
+app_operator_app_status_last_transition_timestamp_seconds{name="hello-world-app",namespace="hello-world"} 1.704111e+09
+# HELP app_operator_app_status_transitions_total Number of observed changes of the release status of the app.
+# TYPE app_operator_app_status_transitions_total counter
+app_operator_app_status_transitions_total{from="failed",name="hello-world-app",namespace="hello-world",to="deployed"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: a},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "failure_reason"),
+		prometheus.BuildFQName(namespace, "app", "last_deployed_timestamp_seconds"),
+		prometheus.BuildFQName(namespace, "app", "release_status_age_seconds"),
+		prometheus.BuildFQName(namespace, "app", "status_last_transition_timestamp_seconds"),
+		prometheus.BuildFQName(namespace, "app", "status_transitions_total"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+}
+
+func Test_getCatalogAppVersions(t *testing.T) {
 	tests := []struct {
 		name             string
 		catalogs         []*v1alpha1.Catalog
 		catalogsEntries  []*v1alpha1.AppCatalogEntry
+		catalogAllowlist []string
+		catalogDenylist  []string
 		expectedVersions map[string]string
+		expectedReleased map[string]int
 	}{
 		{
 			name: "flawless",
@@ -289,8 +940,12 @@ func Test_getLatestAppVersions(t *testing.T) {
 				newACE("example", "customer", "default", "0.1.0", "", "", false),
 			},
 			expectedVersions: map[string]string{
-				"customer-example":           "1.0.0",
-				"giantswarm-hello-world-app": "0.3.0",
+				"default/customer/example":           "1.0.0",
+				"default/giantswarm/hello-world-app": "0.3.0",
+			},
+			expectedReleased: map[string]int{
+				"default/customer/example":           3,
+				"default/giantswarm/hello-world-app": 2,
 			},
 		},
 		{
@@ -307,8 +962,88 @@ func Test_getLatestAppVersions(t *testing.T) {
 				newACE("example", "customer", "default", "v0.1.0", "", "", false),
 			},
 			expectedVersions: map[string]string{
-				"customer-example":           "1.0.0",
-				"giantswarm-hello-world-app": "0.3.0",
+				"default/customer/example":           "1.0.0",
+				"default/giantswarm/hello-world-app": "0.3.0",
+			},
+			expectedReleased: map[string]int{
+				"default/customer/example":           3,
+				"default/giantswarm/hello-world-app": 2,
+			},
+		},
+		{
+			name: "private catalog not selected by default",
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("giantswarm", "default"),
+				newCatalogWithLabels("internal", "org-acme", nil),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+				newACE("example", "internal", "org-acme", "v1.0.0", "", "", true),
+			},
+			expectedVersions: map[string]string{
+				"default/giantswarm/hello-world-app": "0.3.0",
+			},
+			expectedReleased: map[string]int{
+				"default/giantswarm/hello-world-app": 1,
+			},
+		},
+		{
+			name: "allowlisted private catalog with v* versions",
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("giantswarm", "default"),
+				newCatalogWithLabels("internal", "org-acme", nil),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+				newACE("example", "internal", "org-acme", "v1.0.0", "", "", true),
+				newACE("example", "internal", "org-acme", "v0.9.0", "", "", false),
+			},
+			catalogAllowlist: []string{"org-acme/internal"},
+			expectedVersions: map[string]string{
+				"default/giantswarm/hello-world-app": "0.3.0",
+				"org-acme/internal/example":          "1.0.0",
+			},
+			expectedReleased: map[string]int{
+				"default/giantswarm/hello-world-app": 1,
+				"org-acme/internal/example":          2,
+			},
+		},
+		{
+			name: "denylisted public catalog",
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("giantswarm", "default"),
+				newCatalog("customer", "default"),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("hello-world-app", "giantswarm", "default", "0.3.0", "", "", true),
+				newACE("example", "customer", "default", "1.0.0", "", "", true),
+			},
+			catalogDenylist: []string{"customer"},
+			expectedVersions: map[string]string{
+				"default/giantswarm/hello-world-app": "0.3.0",
+			},
+			expectedReleased: map[string]int{
+				"default/giantswarm/hello-world-app": 1,
+			},
+		},
+		{
+			name: "catalogs with the same name in different namespaces",
+			catalogs: []*v1alpha1.Catalog{
+				newCatalog("internal", "org-acme"),
+				newCatalog("internal", "org-globex"),
+			},
+			catalogsEntries: []*v1alpha1.AppCatalogEntry{
+				newACE("example", "internal", "org-acme", "1.0.0", "", "", true),
+				newACE("example", "internal", "org-globex", "2.0.0", "", "", true),
+				newACE("example", "internal", "org-globex", "1.0.0", "", "", false),
+			},
+			expectedVersions: map[string]string{
+				"org-acme/internal/example":   "1.0.0",
+				"org-globex/internal/example": "2.0.0",
+			},
+			expectedReleased: map[string]int{
+				"org-acme/internal/example":   1,
+				"org-globex/internal/example": 2,
 			},
 		},
 	}
@@ -348,6 +1083,8 @@ func Test_getLatestAppVersions(t *testing.T) {
 				K8sClient: k8sClientFake,
 				Logger:    microloggertest.New(),
 
+				CatalogAllowlist:    tc.catalogAllowlist,
+				CatalogDenylist:     tc.catalogDenylist,
 				DefaultTeam:         "honeybadger",
 				Provider:            "aws",
 				RetiredTeamsMapping: map[string]string{},
@@ -358,14 +1095,30 @@ func Test_getLatestAppVersions(t *testing.T) {
 				t.Fatalf("error == %#v, want nil", err)
 			}
 
-			latestAppVersions, err := app.getLatestAppVersions(context.TODO())
+			catalogs, err := app.listCatalogs(context.TODO())
 			if err != nil {
 				t.Fatalf("error == %#v, want nil", err)
 			}
 
+			catalogAppVersions, err := app.getCatalogAppVersions(context.TODO(), catalogs)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			latestAppVersions := map[string]string{}
+			released := map[string]int{}
+			for k, v := range catalogAppVersions {
+				name := fmt.Sprintf("%s/%s/%s", k.namespace, k.catalog, k.app)
+				latestAppVersions[name] = v.latest
+				released[name] = len(v.released)
+			}
+
 			if !reflect.DeepEqual(latestAppVersions, tc.expectedVersions) {
 				t.Fatalf("want matching resources \n %s", cmp.Diff(latestAppVersions, tc.expectedVersions))
 			}
+			if !reflect.DeepEqual(released, tc.expectedReleased) {
+				t.Fatalf("want matching released versions \n %s", cmp.Diff(released, tc.expectedReleased))
+			}
 		})
 	}
 }
@@ -522,11 +1275,16 @@ func Test_getTeamMappings(t *testing.T) {
 				t.Fatalf("error == %#v, want nil", err)
 			}
 
-			teamsMappings, err := app.getTeamMappings(context.TODO(), tc.apps)
+			candidates, err := app.getTeamMappings(context.TODO(), tc.apps, app.teamMappings.Load())
 			if err != nil {
 				t.Fatalf("error == %#v, want nil", err)
 			}
 
+			teamsMappings := map[string]string{}
+			for name, c := range candidates {
+				teamsMappings[name] = decideTeam(c, app.teamMappings.Load(), "").Team
+			}
+
 			if !reflect.DeepEqual(teamsMappings, tc.expectedTeamMappings) {
 				t.Fatalf("want matching resources \n %s", cmp.Diff(teamsMappings, tc.expectedTeamMappings))
 			}
@@ -613,16 +1371,80 @@ func newApp(name, catalog, namespace, version, statusVersion, statusRelease stri
 	return &app
 }
 
+// newFakeApp creates an App collector for tests. If the config has no
+// K8sClient, one holding the given objects is created. The default team,
+// provider and retired teams are set to the ones used throughout these tests
+// unless configured.
+func newFakeApp(t *testing.T, config AppConfig, objects ...runtime.Object) *App {
+	t.Helper()
+
+	if config.K8sClient == nil {
+		config.K8sClient = newFakeClients(t, interceptor.Funcs{}, objects...)
+	}
+	if config.Logger == nil {
+		config.Logger = microloggertest.New()
+	}
+	if config.DefaultTeam == "" {
+		config.DefaultTeam = "honeybadger"
+	}
+	if config.Provider == "" {
+		config.Provider = "aws"
+	}
+	if config.RetiredTeamsMapping == nil {
+		config.RetiredTeamsMapping = map[string]string{}
+	}
+
+	app, err := NewApp(config)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	return app
+}
+
+// newFakeClients returns clients with a fake controller-runtime client
+// holding the given objects. Its requests pass the given interceptor funcs.
+func newFakeClients(t *testing.T, funcs interceptor.Funcs, objects ...runtime.Object) *k8sclienttest.Clients {
+	t.Helper()
+
+	schemeBuilder := runtime.SchemeBuilder{
+		v1alpha1.AddToScheme,
+	}
+
+	err := schemeBuilder.AddToScheme(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+		CtrlClient: clientfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithRuntimeObjects(objects...).
+			WithInterceptorFuncs(funcs).
+			Build(),
+	})
+}
+
+func withCatalogNamespace(app *v1alpha1.App, namespace string) *v1alpha1.App {
+	app.Spec.CatalogNamespace = namespace
+
+	return app
+}
+
 func newCatalog(name, namespace string) *v1alpha1.Catalog {
+	return newCatalogWithLabels(name, namespace, map[string]string{
+		label.CatalogVisibility: "public",
+	})
+}
+
+func newCatalogWithLabels(name, namespace string, labels map[string]string) *v1alpha1.Catalog {
 	catalog := v1alpha1.Catalog{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Catalog",
 			APIVersion: "application.giantswarm.io/v1alpha1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Labels: map[string]string{
-				label.CatalogVisibility: "public",
-			},
+			Labels:    labels,
 			Name:      name,
 			Namespace: namespace,
 		},
@@ -1,6 +1,18 @@
 package collector
 
+import (
+	"github.com/giantswarm/k8smetadata/pkg/label"
+)
+
+// DefaultCatalogLabelSelector selects the public catalogs to check for
+// upgrades.
+//
+// TODO: Remove community once helm-stable catalog is removed.
+// https://github.com/giantswarm/giantswarm/issues/17490
+const DefaultCatalogLabelSelector = label.CatalogVisibility + "=public," + label.CatalogType + "!=community"
+
 const (
+	deployedStatus     string  = "deployed"
 	gaugeValue         float64 = 1
 	namespace          string  = "app_operator"
 	notInstalledStatus string  = "not-installed"
@@ -10,13 +22,26 @@ const (
 	labelApp              = "app"
 	labelAppVersion       = "app_version"
 	labelCatalog          = "catalog"
+	labelCatalogNamespace = "catalog_namespace"
 	labelClusterMissing   = "cluster_missing"
+	labelCollector        = "collector"
+	labelCycle            = "cycle"
+	labelDependency       = "dependency"
 	labelDeployedVersion  = "deployed_version"
+	labelFrom             = "from"
+	labelHash             = "hash"
+	labelKind             = "kind"
 	labelLatestVersion    = "latest_version"
 	labelName             = "name"
 	labelNamespace        = "namespace"
+	labelReason           = "reason"
+	labelReasonClass      = "reason_class"
+	labelRefName          = "ref_name"
+	labelRefNamespace     = "ref_namespace"
+	labelStage            = "stage"
 	labelStatus           = "status"
 	labelTeam             = "team"
+	labelTo               = "to"
 	labelUpgradeAvailable = "upgrade_available"
 	labelVersion          = "version"
 	labelVersionMismatch  = "version_mismatch"
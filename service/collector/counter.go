@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"sync"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// appCounter counts occurrences per App CR between collections so they can
+// be exposed as counters labelled by App CR.
+type appCounter struct {
+	mutex  sync.Mutex
+	counts map[types.NamespacedName]int
+}
+
+// inc increments the count of the App CR.
+func (c *appCounter) inc(app v1alpha1.App) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.counts == nil {
+		c.counts = map[types.NamespacedName]int{}
+	}
+
+	c.counts[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}]++
+}
+
+// list returns a copy of the counts of all App CRs.
+func (c *appCounter) list() map[types.NamespacedName]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	counts := make(map[types.NamespacedName]int, len(c.counts))
+	for n, count := range c.counts {
+		counts[n] = count
+	}
+
+	return counts
+}
+
+// prune removes the counts of all App CRs which are not in the given list so
+// deleted App CRs do not leak memory or series.
+func (c *appCounter) prune(apps []v1alpha1.App) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	seen := map[types.NamespacedName]bool{}
+	for _, app := range apps {
+		seen[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}] = true
+	}
+
+	for n := range c.counts {
+		if !seen[n] {
+			delete(c.counts, n)
+		}
+	}
+}
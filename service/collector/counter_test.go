@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_appCounter(t *testing.T) {
+	app := *newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil)
+	other := *newApp("example", "customer", "default", "1.0.0", "", "", nil, nil)
+
+	c := appCounter{}
+
+	c.inc(app)
+	c.inc(app)
+	c.inc(other)
+
+	expected := map[types.NamespacedName]int{
+		{Namespace: "hello-world", Name: "hello-world-app"}: 2,
+		{Namespace: "default", Name: "example"}:             1,
+	}
+	if counts := c.list(); !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("counts == %v, want %v", counts, expected)
+	}
+
+	// Counts of deleted App CRs are removed.
+	c.prune([]v1alpha1.App{app})
+
+	expected = map[types.NamespacedName]int{
+		{Namespace: "hello-world", Name: "hello-world-app"}: 2,
+	}
+	if counts := c.list(); !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("counts == %v, want %v", counts, expected)
+	}
+}
@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/app/v7/pkg/key"
+	"k8s.io/apimachinery/pkg/types"
+
+	expkey "github.com/giantswarm/app-exporter/internal/key"
+)
+
+// dependencyGraph holds the dependencies between the App CRs of each cluster.
+// A cluster is identified by the namespace and cluster label of its App CRs.
+type dependencyGraph struct {
+	apps     map[types.NamespacedName]v1alpha1.App
+	clusters map[string][]types.NamespacedName
+	edges    map[types.NamespacedName][]types.NamespacedName
+}
+
+func newDependencyGraph(apps []v1alpha1.App) *dependencyGraph {
+	g := &dependencyGraph{
+		apps:     map[types.NamespacedName]v1alpha1.App{},
+		clusters: map[string][]types.NamespacedName{},
+		edges:    map[types.NamespacedName][]types.NamespacedName{},
+	}
+
+	for _, app := range apps {
+		n := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+		g.apps[n] = app
+		g.clusters[clusterKey(app)] = append(g.clusters[clusterKey(app)], n)
+	}
+
+	for n, app := range g.apps {
+		for _, dependency := range expkey.DependsOn(app) {
+			if d, ok := g.resolve(app, dependency); ok {
+				g.edges[n] = append(g.edges[n], d)
+			}
+		}
+	}
+
+	return g
+}
+
+// unmet returns the dependencies of the App CR which are missing or not
+// deployed. app-operator does not install the App CR until all of them are
+// deployed. Missing dependencies are only reported when complete is true, i.e.
+// the graph holds all App CRs of the cluster.
+func (g *dependencyGraph) unmet(app v1alpha1.App, complete bool) []string {
+	var unmet []string
+
+	for _, dependency := range expkey.DependsOn(app) {
+		d, ok := g.resolve(app, dependency)
+		if !ok && !complete {
+			continue
+		}
+
+		if !ok || g.apps[d].Status.Release.Status != deployedStatus {
+			unmet = append(unmet, dependency)
+		}
+	}
+
+	return unmet
+}
+
+// cycles returns the App CRs which are part of a dependency cycle. Such apps
+// are never installed. Each of them is mapped to the sorted, comma separated
+// names of all App CRs in its cycle.
+func (g *dependencyGraph) cycles() map[types.NamespacedName]string {
+	cycles := map[types.NamespacedName]string{}
+
+	for _, component := range g.stronglyConnectedComponents() {
+		n := component[0]
+		if len(component) == 1 && !slices.Contains(g.edges[n], n) {
+			continue
+		}
+
+		var names []string
+		for _, c := range component {
+			names = append(names, c.Name)
+		}
+		slices.Sort(names)
+
+		for _, c := range component {
+			cycles[c] = strings.Join(names, ",")
+		}
+	}
+
+	return cycles
+}
+
+// resolve returns the App CR of the same cluster the dependency refers to. A
+// dependency matches the name of an App CR or else its spec.name.
+func (g *dependencyGraph) resolve(app v1alpha1.App, dependency string) (types.NamespacedName, bool) {
+	cluster := g.clusters[clusterKey(app)]
+
+	for _, n := range cluster {
+		if n.Name == dependency {
+			return n, true
+		}
+	}
+	for _, n := range cluster {
+		if key.AppName(g.apps[n]) == dependency {
+			return n, true
+		}
+	}
+
+	return types.NamespacedName{}, false
+}
+
+// stronglyConnectedComponents returns the strongly connected components of
+// the graph using Tarjan's algorithm. Nodes are visited in a sorted order so
+// the result is stable.
+func (g *dependencyGraph) stronglyConnectedComponents() [][]types.NamespacedName {
+	var components [][]types.NamespacedName
+
+	index := 0
+	indices := map[types.NamespacedName]int{}
+	lowlinks := map[types.NamespacedName]int{}
+	onStack := map[types.NamespacedName]bool{}
+	var stack []types.NamespacedName
+
+	var connect func(n types.NamespacedName)
+	connect = func(n types.NamespacedName) {
+		indices[n] = index
+		lowlinks[n] = index
+		index++
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, d := range g.edges[n] {
+			if _, visited := indices[d]; !visited {
+				connect(d)
+				lowlinks[n] = min(lowlinks[n], lowlinks[d])
+			} else if onStack[d] {
+				lowlinks[n] = min(lowlinks[n], indices[d])
+			}
+		}
+
+		if lowlinks[n] != indices[n] {
+			return
+		}
+
+		var component []types.NamespacedName
+		for {
+			c := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[c] = false
+			component = append(component, c)
+
+			if c == n {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+
+	nodes := make([]types.NamespacedName, 0, len(g.apps))
+	for n := range g.apps {
+		nodes = append(nodes, n)
+	}
+	slices.SortFunc(nodes, func(a, b types.NamespacedName) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, n := range nodes {
+		if _, visited := indices[n]; !visited {
+			connect(n)
+		}
+	}
+
+	return components
+}
+
+// clusterKey identifies the cluster an App CR is installed to.
+func clusterKey(app v1alpha1.App) string {
+	return app.Namespace + "/" + key.ClusterLabel(app)
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+
+	expkey "github.com/giantswarm/app-exporter/internal/key"
+)
+
+func Test_dependencyGraph(t *testing.T) {
+	newDependentApp := func(name, namespace, status, dependsOn string) v1alpha1.App {
+		return *newApp(name, "default", namespace, "1.0.0", "", status, map[string]string{
+			expkey.AnnotationDependsOn: dependsOn,
+		}, nil)
+	}
+
+	apps := []v1alpha1.App{
+		newDependentApp("a", "org-acme", "not-installed", "b"),
+		newDependentApp("b", "org-acme", "not-installed", "c"),
+		newDependentApp("c", "org-acme", "not-installed", "a,d"),
+		newDependentApp("d", "org-acme", "deployed", ""),
+		newDependentApp("e", "org-acme", "not-installed", "c,missing"),
+		// Apps of other namespaces are other clusters.
+		newDependentApp("a", "org-other", "deployed", "missing"),
+	}
+
+	g := newDependencyGraph(apps)
+
+	testCases := []struct {
+		name     string
+		app      v1alpha1.App
+		complete bool
+		expected []string
+	}{
+		{
+			name:     "case 0: not deployed dependency in a cycle",
+			app:      apps[2],
+			complete: true,
+			expected: []string{"a"},
+		},
+		{
+			name:     "case 1: missing dependency",
+			app:      apps[4],
+			complete: true,
+			expected: []string{"c", "missing"},
+		},
+		{
+			name:     "case 2: missing dependency is skipped when the graph is incomplete",
+			app:      apps[4],
+			complete: false,
+			expected: []string{"c"},
+		},
+		{
+			name:     "case 3: apps of other clusters are no dependency",
+			app:      apps[5],
+			complete: true,
+			expected: []string{"missing"},
+		},
+		{
+			name:     "case 4: no dependencies",
+			app:      apps[3],
+			complete: true,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			unmet := g.unmet(tc.app, tc.complete)
+			if !reflect.DeepEqual(unmet, tc.expected) {
+				t.Fatalf("unmet == %v, want %v", unmet, tc.expected)
+			}
+		})
+	}
+
+	cycles := g.cycles()
+	if len(cycles) != 3 {
+		t.Fatalf("len(cycles) == %d, want 3", len(cycles))
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if cycle := cycles[types.NamespacedName{Namespace: "org-acme", Name: name}]; cycle != "a,b,c" {
+			t.Fatalf("cycle of %#q == %#q, want %#q", name, cycle, "a,b,c")
+		}
+	}
+}
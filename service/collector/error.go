@@ -21,3 +21,21 @@ var invalidExecutionError = &microerror.Error{
 func IsInvalidExecution(err error) bool {
 	return microerror.Cause(err) == invalidExecutionError
 }
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
+
+var abandonedError = &microerror.Error{
+	Kind: "abandonedError",
+}
+
+// IsAbandoned asserts abandonedError.
+func IsAbandoned(err error) bool {
+	return microerror.Cause(err) == abandonedError
+}
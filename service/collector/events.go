@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	eventReasonCordonExpired   = "CordonExpired"
+	eventReasonReleaseFailed   = "ReleaseFailed"
+	eventReasonVersionMismatch = "VersionMismatch"
+)
+
+// versionMismatchEventAfter is for how long the desired and deployed version
+// of an App CR must differ before an event is emitted. Upgrades take a while
+// so short mismatches are expected.
+const versionMismatchEventAfter = 15 * time.Minute
+
+// failedStatuses are the release statuses for which an event is emitted when
+// an App CR goes into them.
+var failedStatuses = map[string]bool{
+	"already-exists":    true,
+	"chart-pull-failed": true,
+	"failed":            true,
+	"invalid-manifest":  true,
+	"validation-failed": true,
+}
+
+// appEvents emits Kubernetes events on App CRs when their health changes. Each
+// event is emitted once per change and not on every collection. The event
+// recorder aggregates and rate limits the events further. A nil *appEvents is
+// valid and emits nothing so events can be disabled.
+type appEvents struct {
+	recorder record.EventRecorder
+
+	mutex  sync.Mutex
+	states map[types.NamespacedName]eventState
+}
+
+// eventState is what is remembered per App CR to emit each event only once.
+type eventState struct {
+	cordonExpiredReported   string
+	versionMismatchSince    time.Time
+	versionMismatchReported bool
+}
+
+func newAppEvents(recorder record.EventRecorder) *appEvents {
+	if recorder == nil {
+		return nil
+	}
+
+	e := &appEvents{
+		recorder: recorder,
+
+		states: map[types.NamespacedName]eventState{},
+	}
+
+	return e
+}
+
+// statusObserved emits an event when the release status of the App CR just
+// changed into a failed status.
+func (e *appEvents) statusObserved(app *v1alpha1.App, observed observedStatus, now time.Time) {
+	if e == nil {
+		return
+	}
+
+	if !failedStatuses[observed.status] || !observed.lastTransition.Equal(now) {
+		return
+	}
+
+	if app.Status.Release.Reason == "" {
+		e.recorder.Eventf(app, corev1.EventTypeWarning, eventReasonReleaseFailed, "Release status changed to %#q", observed.status)
+		return
+	}
+
+	e.recorder.Eventf(app, corev1.EventTypeWarning, eventReasonReleaseFailed, "Release status changed to %#q: %s", observed.status, app.Status.Release.Reason)
+}
+
+// versionObserved emits an event once the desired and deployed version of the
+// App CR differ for longer than versionMismatchEventAfter.
+func (e *appEvents) versionObserved(app *v1alpha1.App, desired, deployed string, now time.Time) {
+	if e == nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	n := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	s := e.states[n]
+
+	if desired == deployed {
+		s.versionMismatchSince = time.Time{}
+		s.versionMismatchReported = false
+		e.states[n] = s
+		return
+	}
+
+	if s.versionMismatchSince.IsZero() {
+		s.versionMismatchSince = now
+	}
+
+	if !s.versionMismatchReported && now.Sub(s.versionMismatchSince) >= versionMismatchEventAfter {
+		e.recorder.Eventf(app, corev1.EventTypeWarning, eventReasonVersionMismatch, "Version %#q is desired but %#q is deployed since %s", desired, deployed, s.versionMismatchSince.Format(time.RFC3339))
+		s.versionMismatchReported = true
+	}
+
+	e.states[n] = s
+}
+
+// cordonObserved emits an event once the cordon of the App CR has expired.
+// Each cordon-until value is reported once.
+func (e *appEvents) cordonObserved(app *v1alpha1.App, cordonUntil string, until time.Time, now time.Time) {
+	if e == nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	n := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	s := e.states[n]
+
+	if until.After(now) || s.cordonExpiredReported == cordonUntil {
+		return
+	}
+
+	e.recorder.Eventf(app, corev1.EventTypeWarning, eventReasonCordonExpired, "Cordon expired at %s but the cordon annotations were not removed", until.Format(time.RFC3339))
+	s.cordonExpiredReported = cordonUntil
+
+	e.states[n] = s
+}
+
+// prune removes the state of all App CRs which are not in the given list so
+// deleted App CRs do not leak memory.
+func (e *appEvents) prune(apps []v1alpha1.App) {
+	if e == nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	seen := map[types.NamespacedName]bool{}
+	for _, app := range apps {
+		seen[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}] = true
+	}
+
+	for n := range e.states {
+		if !seen[n] {
+			delete(e.states, n)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+)
+
+func Test_appEvents(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	recorder := record.NewFakeRecorder(10)
+	e := newAppEvents(recorder)
+
+	app := newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "failed", nil, nil)
+	app.Status.Release.Reason = "timed out waiting for the condition"
+
+	// Only a transition into a failed status emits an event.
+	e.statusObserved(app, observedStatus{status: "failed", since: now}, now)
+	e.statusObserved(app, observedStatus{status: "failed", since: now, lastTransition: now}, now)
+	e.statusObserved(app, observedStatus{status: "failed", since: now, lastTransition: now}, now.Add(time.Minute))
+	e.statusObserved(app, observedStatus{status: "deployed", since: now, lastTransition: now}, now)
+	expectEvents(t, recorder, "Warning ReleaseFailed Release status changed to `failed`: timed out waiting for the condition")
+
+	// A version mismatch is reported once after it persisted.
+	e.versionObserved(app, "0.3.0", "0.2.0", now)
+	e.versionObserved(app, "0.3.0", "0.2.0", now.Add(10*time.Minute))
+	expectEvents(t, recorder)
+	e.versionObserved(app, "0.3.0", "0.2.0", now.Add(15*time.Minute))
+	e.versionObserved(app, "0.3.0", "0.2.0", now.Add(20*time.Minute))
+	expectEvents(t, recorder, "Warning VersionMismatch Version `0.3.0` is desired but `0.2.0` is deployed since 2024-01-01T12:00:00Z")
+	// Once resolved a new mismatch is reported again.
+	e.versionObserved(app, "0.3.0", "0.3.0", now.Add(21*time.Minute))
+	e.versionObserved(app, "0.4.0", "0.3.0", now.Add(22*time.Minute))
+	e.versionObserved(app, "0.4.0", "0.3.0", now.Add(37*time.Minute))
+	expectEvents(t, recorder, "Warning VersionMismatch Version `0.4.0` is desired but `0.3.0` is deployed since 2024-01-01T12:22:00Z")
+
+	// An expired cordon is reported once per cordon-until value.
+	e.cordonObserved(app, "2024-01-01T13:00:00", now.Add(time.Hour), now)
+	e.cordonObserved(app, "2024-01-01T11:00:00", now.Add(-time.Hour), now)
+	e.cordonObserved(app, "2024-01-01T11:00:00", now.Add(-time.Hour), now.Add(time.Minute))
+	expectEvents(t, recorder, "Warning CordonExpired Cordon expired at 2024-01-01T11:00:00Z but the cordon annotations were not removed")
+
+	// A nil *appEvents emits nothing.
+	var disabled *appEvents
+	disabled.statusObserved(app, observedStatus{status: "failed", since: now, lastTransition: now}, now)
+	disabled.versionObserved(app, "0.3.0", "0.2.0", now)
+	disabled.cordonObserved(app, "2024-01-01T11:00:00", now.Add(-time.Hour), now)
+	disabled.prune(nil)
+}
+
+func expectEvents(t *testing.T, recorder *record.FakeRecorder, expected ...string) {
+	t.Helper()
+
+	for _, e := range expected {
+		select {
+		case got := <-recorder.Events:
+			if got != e {
+				t.Fatalf("event == %q, want %q", got, e)
+			}
+		default:
+			t.Fatalf("no event, want %q", e)
+		}
+	}
+
+	select {
+	case got := <-recorder.Events:
+		t.Fatalf("unexpected event %q", got)
+	default:
+	}
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"context"
+	"slices"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listApps lists the App CRs in the watched namespaces which match the given
+// label selector.
+func listApps(ctx context.Context, reader client.Reader, namespaces []string, selector labels.Selector) ([]v1alpha1.App, error) {
+	var apps []v1alpha1.App
+
+	for _, ns := range listNamespaces(namespaces) {
+		l := &v1alpha1.AppList{}
+		err := reader.List(ctx, l, &client.ListOptions{Namespace: ns, LabelSelector: selector})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		apps = append(apps, l.Items...)
+	}
+
+	return apps, nil
+}
+
+// listNamespaces returns the namespaces to run List calls in. When no
+// namespaces are configured a single List across all namespaces is done.
+func listNamespaces(namespaces []string) []string {
+	if len(namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+
+	return namespaces
+}
+
+// isWatchedNamespace returns true if the given namespace is one of the
+// configured namespaces or if the collection is not namespace scoped.
+func isWatchedNamespace(namespaces []string, namespace string) bool {
+	return len(namespaces) == 0 || slices.Contains(namespaces, namespace)
+}
@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"strings"
+)
+
+const (
+	reasonClassChartPullFailed   = "chart-pull-failed"
+	reasonClassKubeConfigMissing = "kubeconfig-missing"
+	reasonClassTimeout           = "timeout"
+	reasonClassUnknown           = "unknown"
+	reasonClassUpgradeFailed     = "upgrade-failed"
+	reasonClassValidationFailed  = "validation-failed"
+)
+
+// reasonClassRule maps release statuses and failure reasons containing any of
+// the given substrings to a reason class.
+type reasonClassRule struct {
+	class      string
+	statuses   []string
+	substrings []string
+}
+
+// reasonClassRules are checked in order, the first matching rule wins. More
+// specific causes like a missing kubeconfig come first as Helm wraps them
+// into generic install or upgrade errors.
+var reasonClassRules = []reasonClassRule{
+	{
+		class: reasonClassKubeConfigMissing,
+		substrings: []string{
+			"kubeconfig",
+		},
+	},
+	{
+		class: reasonClassTimeout,
+		substrings: []string{
+			"context deadline exceeded",
+			"timed out",
+			"timeout",
+		},
+	},
+	{
+		class: reasonClassChartPullFailed,
+		statuses: []string{
+			"chart-pull-failed",
+		},
+		substrings: []string{
+			"chart not found",
+			"failed to download",
+			"failed to pull",
+			"pulling chart",
+		},
+	},
+	{
+		class: reasonClassValidationFailed,
+		statuses: []string{
+			"invalid-manifest",
+			"validation-failed",
+		},
+		substrings: []string{
+			"invalid",
+			"schema",
+			"validation",
+		},
+	},
+	{
+		class: reasonClassUpgradeFailed,
+		statuses: []string{
+			"pending-rollback",
+			"pending-upgrade",
+		},
+		substrings: []string{
+			"rollback",
+			"upgrade",
+		},
+	},
+}
+
+// classifyFailureReason maps the release status and the free text failure
+// reason of an App CR into a bounded set of reason classes so it can be
+// exposed as a label.
+func classifyFailureReason(status, reason string) string {
+	status = strings.ToLower(status)
+	reason = strings.ToLower(reason)
+
+	for _, rule := range reasonClassRules {
+		for _, s := range rule.statuses {
+			if status == s {
+				return rule.class
+			}
+		}
+		for _, s := range rule.substrings {
+			if strings.Contains(reason, s) {
+				return rule.class
+			}
+		}
+	}
+
+	return reasonClassUnknown
+}
@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"testing"
+)
+
+func Test_classifyFailureReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		reason   string
+		expected string
+	}{
+		{
+			name:     "case 0: chart pull failed status",
+			status:   "chart-pull-failed",
+			reason:   "failed to get chart tarball",
+			expected: reasonClassChartPullFailed,
+		},
+		{
+			name:     "case 1: chart pull failed reason",
+			status:   "failed",
+			reason:   "failed to download \"https://example.com/hello-world-app-0.3.0.tgz\"",
+			expected: reasonClassChartPullFailed,
+		},
+		{
+			name:     "case 2: validation failed status",
+			status:   "validation-failed",
+			reason:   "app config map not found",
+			expected: reasonClassValidationFailed,
+		},
+		{
+			name:     "case 3: schema validation",
+			status:   "failed",
+			reason:   "values don't meet the specifications of the schema(s) in the following chart(s)",
+			expected: reasonClassValidationFailed,
+		},
+		{
+			name:     "case 4: upgrade failed",
+			status:   "failed",
+			reason:   "UPGRADE FAILED: cannot patch \"hello-world\" with kind Deployment",
+			expected: reasonClassUpgradeFailed,
+		},
+		{
+			name:     "case 5: missing kubeconfig wins over upgrade",
+			status:   "failed",
+			reason:   "upgrade failed: kubeconfig secret \"foo-kubeconfig\" in namespace \"foo\" not found",
+			expected: reasonClassKubeConfigMissing,
+		},
+		{
+			name:     "case 6: timeout",
+			status:   "failed",
+			reason:   "timed out waiting for the condition",
+			expected: reasonClassTimeout,
+		},
+		{
+			name:     "case 7: context deadline",
+			status:   "pending-install",
+			reason:   "context deadline exceeded",
+			expected: reasonClassTimeout,
+		},
+		{
+			name:     "case 8: unknown",
+			status:   "failed",
+			reason:   "rendered manifests contain a resource that already exists",
+			expected: reasonClassUnknown,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyFailureReason(tc.status, tc.reason)
+			if got != tc.expected {
+				t.Errorf("classifyFailureReason() = %#q, want %#q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	selfNamespace = "app_exporter"
+	selfSubsystem = "collector"
+)
+
+const (
+	collectorApp         = "app"
+	collectorAppOperator = "app_operator"
+)
+
+const (
+	stageCatalogEntries = "catalog_entries"
+	stageCatalogs       = "catalogs"
+	stageConfigs        = "configs"
+	stageDeployments    = "deployments"
+	stageLatestVersions = "latest_versions"
+	stageListApps       = "list_apps"
+	stageTeams          = "teams"
+	stageTimeout        = "timeout"
+)
+
+var (
+	collectorErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: selfNamespace,
+			Subsystem: selfSubsystem,
+			Name:      "errors_total",
+			Help:      "Number of errors per collector and stage of the collection.",
+		},
+		[]string{
+			labelCollector,
+			labelStage,
+		},
+	)
+
+	collectorLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: selfNamespace,
+			Subsystem: selfSubsystem,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last collection which completed without any error.",
+		},
+		[]string{
+			labelCollector,
+		},
+	)
+
+	collectorStale = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: selfNamespace,
+			Subsystem: selfSubsystem,
+			Name:      "stale",
+			Help:      "Whether the collector serves the metrics of an earlier collection because the last one timed out.",
+		},
+		[]string{
+			labelCollector,
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(collectorErrorsTotal)
+	prometheus.MustRegister(collectorLastSuccessTimestamp)
+	prometheus.MustRegister(collectorStale)
+}
+
+// recordError increments the error counter of the given collector and stage.
+func recordError(collector, stage string) {
+	collectorErrorsTotal.WithLabelValues(collector, stage).Inc()
+}
+
+// recordSuccess sets the last success timestamp of the given collector to now.
+func recordSuccess(collector string) {
+	collectorLastSuccessTimestamp.WithLabelValues(collector).Set(float64(time.Now().Unix()))
+}
@@ -1,20 +1,38 @@
 package collector
 
 import (
+	"context"
+	"time"
+
 	"github.com/giantswarm/exporterkit/collector"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type SetConfig struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
+	EventRecorder record.EventRecorder
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+	Reader        client.Reader
 
-	AppTeamMappings     map[string]string
-	DefaultTeam         string
-	Provider            string
-	RetiredTeamsMapping map[string]string
+	AppTeamMappings      map[string]string
+	CatalogAllowlist     []string
+	CatalogDenylist      []string
+	CatalogLabelSelector labels.Selector
+	ConfigReferences     bool
+	DefaultTeam          string
+	KnownTeams           []string
+	KubeConfigSecrets    bool
+	LabelSelector        labels.Selector
+	Namespaces           []string
+	Provider             string
+	RetiredTeamsMapping  map[string]string
+	Timeout              time.Duration
+	UnknownTeamFallback  bool
 }
 
 // Set is basically only a wrapper for the operator's collector implementations.
@@ -22,6 +40,8 @@ type SetConfig struct {
 // have to alias packages.
 type Set struct {
 	*collector.Set
+
+	app *App
 }
 
 func NewSet(config SetConfig) (*Set, error) {
@@ -53,6 +73,11 @@ func NewSet(config SetConfig) (*Set, error) {
 		c := AppOperatorConfig{
 			K8sClient: config.K8sClient,
 			Logger:    config.Logger,
+			Reader:    config.Reader,
+
+			LabelSelector: config.LabelSelector,
+			Namespaces:    config.Namespaces,
+			Timeout:       config.Timeout,
 		}
 
 		appOperatorCollector, err = NewAppOperator(c)
@@ -79,7 +104,25 @@ func NewSet(config SetConfig) (*Set, error) {
 
 	s := &Set{
 		Set: collectorSet,
+
+		app: appCollector,
 	}
 
 	return s, nil
 }
+
+// ExplainTeam returns the candidate teams of the given App CR and the team it
+// is attributed to.
+func (s *Set) ExplainTeam(ctx context.Context, namespace, name string) (TeamExplanation, error) {
+	e, err := s.app.ExplainTeam(ctx, namespace, name)
+	if err != nil {
+		return TeamExplanation{}, microerror.Mask(err)
+	}
+
+	return e, nil
+}
+
+// SetTeamMappings atomically replaces the team mappings of the app collector.
+func (s *Set) SetTeamMappings(m TeamMappings) {
+	s.app.SetTeamMappings(m)
+}
@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// snapshot holds the metrics of the last collection which finished within
+// its deadline. It is served instead when a collection times out.
+type snapshot struct {
+	mutex   sync.Mutex
+	metrics []prometheus.Metric
+}
+
+func (s *snapshot) get() []prometheus.Metric {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.metrics
+}
+
+func (s *snapshot) set(metrics []prometheus.Metric) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.metrics = metrics
+}
+
+// collection is handed to each collect function. Collections which did not
+// finish within their deadline are abandoned but keep running in the
+// background until they return. Abandoned collections must not update the
+// state collectors keep between collections, e.g. the release statuses, as
+// they would race with the following collections.
+type collection struct {
+	mutex     sync.Mutex
+	abandoned bool
+}
+
+// abandon stops the collection from updating any state. It waits for a
+// running update to finish.
+func (c *collection) abandon() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.abandoned = true
+}
+
+// update runs f unless the collection was abandoned. It returns an
+// abandonedError otherwise so the collection can stop early.
+func (c *collection) update(f func()) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.abandoned {
+		return microerror.Mask(abandonedError)
+	}
+
+	f()
+
+	return nil
+}
+
+// collectWithTimeout runs the given collect function with a context which is
+// cancelled after the given timeout. A timeout of zero disables it. When the
+// deadline is hit the collection is abandoned, the metrics of the last good
+// collection are emitted and the collector is marked as stale. The collect
+// function is not waited for as it may ignore the context.
+func collectWithTimeout(logger micrologger.Logger, collector string, timeout time.Duration, s *snapshot, ch chan<- prometheus.Metric, collect func(context.Context, *collection, chan<- prometheus.Metric) error) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c := &collection{}
+	buffer := make(chan prometheus.Metric)
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(buffer)
+		done <- collect(ctx, c, buffer)
+	}()
+
+	var metrics []prometheus.Metric
+	var err error
+	for finished := false; !finished; {
+		select {
+		case m, ok := <-buffer:
+			if !ok {
+				err = <-done
+				finished = true
+				break
+			}
+			metrics = append(metrics, m)
+		case <-ctx.Done():
+			c.abandon()
+			// The abandoned collection is drained so it does not block
+			// on sending its metrics forever.
+			go func() {
+				for range buffer {
+				}
+			}()
+
+			err = ctx.Err()
+			finished = true
+		}
+	}
+
+	// Collections which noticed the deadline themselves are treated like
+	// abandoned ones. Everything else is decided by what the collection
+	// returned.
+	if errors.Is(err, context.DeadlineExceeded) || IsAbandoned(err) {
+		logger.Errorf(ctx, err, "collection of %#q did not finish within %s, serving last snapshot", collector, timeout)
+		recordError(collector, stageTimeout)
+		collectorStale.WithLabelValues(collector).Set(1)
+
+		for _, m := range s.get() {
+			ch <- m
+		}
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	s.set(metrics)
+	collectorStale.WithLabelValues(collector).Set(0)
+
+	for _, m := range metrics {
+		ch <- m
+	}
+
+	return nil
+}
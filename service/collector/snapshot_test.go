@@ -0,0 +1,161 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus"
+	prometheustest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_collectWithTimeout(t *testing.T) {
+	desc := prometheus.NewDesc("test_metric", "Test metric.", nil, nil)
+
+	s := &snapshot{}
+
+	collect := func(value float64, delay time.Duration) func(context.Context, *collection, chan<- prometheus.Metric) error {
+		return func(ctx context.Context, c *collection, ch chan<- prometheus.Metric) error {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name          string
+		value         float64
+		delay         time.Duration
+		expectedValue float64
+		expectedStale float64
+	}{
+		{
+			name:          "case 0: collection within deadline",
+			value:         1,
+			expectedValue: 1,
+			expectedStale: 0,
+		},
+		{
+			name:          "case 1: collection exceeding deadline serves snapshot",
+			value:         2,
+			delay:         time.Second,
+			expectedValue: 1,
+			expectedStale: 1,
+		},
+		{
+			name:          "case 2: collection within deadline again",
+			value:         3,
+			expectedValue: 3,
+			expectedStale: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := make(chan prometheus.Metric, 10)
+
+			err := collectWithTimeout(microloggertest.New(), "test", 50*time.Millisecond, s, ch, collect(tc.value, tc.delay))
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			close(ch)
+
+			var metrics []prometheus.Metric
+			for m := range ch {
+				metrics = append(metrics, m)
+			}
+			if len(metrics) != 1 {
+				t.Fatalf("expected 1 metric, got %d", len(metrics))
+			}
+
+			value := prometheustest.ToFloat64(prometheus.CollectorFunc(func(ch chan<- prometheus.Metric) { ch <- metrics[0] }))
+			if value != tc.expectedValue {
+				t.Errorf("expected value %v, got %v", tc.expectedValue, value)
+			}
+
+			stale := prometheustest.ToFloat64(collectorStale.WithLabelValues("test"))
+			if stale != tc.expectedStale {
+				t.Errorf("expected stale %v, got %v", tc.expectedStale, stale)
+			}
+		})
+	}
+}
+
+func Test_collectWithTimeoutBlocking(t *testing.T) {
+	desc := prometheus.NewDesc("test_metric", "Test metric.", nil, nil)
+
+	s := &snapshot{}
+	s.set([]prometheus.Metric{
+		prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1),
+	})
+
+	release := make(chan struct{})
+	returned := make(chan error, 1)
+	updated := false
+
+	// The collection ignores the context like the cache reads do and
+	// only returns once it is released.
+	collect := func(ctx context.Context, c *collection, ch chan<- prometheus.Metric) error {
+		<-release
+
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 2)
+
+		err := c.update(func() {
+			updated = true
+		})
+		returned <- err
+
+		return err
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	start := time.Now()
+
+	err := collectWithTimeout(microloggertest.New(), "test-blocking", 50*time.Millisecond, s, ch, collect)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("collection took %s, want it to return at the deadline", elapsed)
+	}
+	close(ch)
+
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(metrics))
+	}
+
+	value := prometheustest.ToFloat64(prometheus.CollectorFunc(func(ch chan<- prometheus.Metric) { ch <- metrics[0] }))
+	if value != 1 {
+		t.Errorf("expected value %v, got %v", 1, value)
+	}
+
+	stale := prometheustest.ToFloat64(collectorStale.WithLabelValues("test-blocking"))
+	if stale != 1 {
+		t.Errorf("expected stale %v, got %v", 1, stale)
+	}
+
+	// The abandoned collection can still send its metrics and return but
+	// must not update any state.
+	close(release)
+
+	select {
+	case err = <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("abandoned collection did not return")
+	}
+	if !IsAbandoned(err) {
+		t.Fatalf("error == %#v, want abandonedError", err)
+	}
+	if updated {
+		t.Fatalf("abandoned collection updated its state")
+	}
+}
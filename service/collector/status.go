@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// observedStatus is the release status of an App CR together with the time
+// since when the App CR is in this status and the status transitions seen so
+// far.
+type observedStatus struct {
+	status string
+	since  time.Time
+	// lastTransition is zero until a change of the status was observed.
+	lastTransition time.Time
+	transitions    map[statusTransition]int
+}
+
+// statusTransition is a change of the release status of an App CR.
+type statusTransition struct {
+	from string
+	to   string
+}
+
+// statusTracker keeps the release status of each App CR between collections
+// so we can tell for how long an App CR has been in its current status and
+// how often it changed.
+type statusTracker struct {
+	mutex    sync.Mutex
+	statuses map[types.NamespacedName]observedStatus
+}
+
+// observe records the given release status of the App CR and returns what is
+// known about its status. When the App CR is seen for the first time, e.g.
+// after a restart of the exporter, the last deployed time of the release is
+// used as the best guess for the start of the current status as it is updated
+// on every status change of the Helm release. This first observation does not
+// count as a transition.
+func (s *statusTracker) observe(app v1alpha1.App, status string, now time.Time) observedStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.statuses == nil {
+		s.statuses = map[types.NamespacedName]observedStatus{}
+	}
+
+	n := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+
+	o, ok := s.statuses[n]
+	switch {
+	case !ok:
+		o = observedStatus{
+			status:      status,
+			since:       now,
+			transitions: map[statusTransition]int{},
+		}
+
+		lastDeployed := app.Status.Release.LastDeployed.Time
+		if !lastDeployed.IsZero() && lastDeployed.Before(now) {
+			o.since = lastDeployed
+		}
+	case o.status != status:
+		o.transitions[statusTransition{from: o.status, to: status}]++
+		o.lastTransition = now
+		o.since = now
+		o.status = status
+	}
+
+	s.statuses[n] = o
+
+	// The transitions are copied as the map is modified by later
+	// observations while the caller reads it.
+	transitions := make(map[statusTransition]int, len(o.transitions))
+	for t, count := range o.transitions {
+		transitions[t] = count
+	}
+	o.transitions = transitions
+
+	return o
+}
+
+// prune removes the status of all App CRs which are not in the given list so
+// deleted App CRs do not leak memory.
+func (s *statusTracker) prune(apps []v1alpha1.App) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	seen := map[types.NamespacedName]bool{}
+	for _, app := range apps {
+		seen[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}] = true
+	}
+
+	for n := range s.statuses {
+		if !seen[n] {
+			delete(s.statuses, n)
+		}
+	}
+}
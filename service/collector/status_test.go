@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_statusTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lastDeployed := start.Add(-time.Hour)
+
+	app := *newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil)
+	app.Status.Release.LastDeployed = metav1.NewTime(lastDeployed)
+
+	other := *newApp("example", "customer", "default", "1.0.0", "", "", nil, nil)
+
+	s := statusTracker{}
+
+	// First observation uses the last deployed time and is no transition.
+	o := s.observe(app, "deployed", start)
+	if !o.since.Equal(lastDeployed) {
+		t.Fatalf("since == %v, want %v", o.since, lastDeployed)
+	}
+	if !o.lastTransition.IsZero() || len(o.transitions) != 0 {
+		t.Fatalf("transitions == %v at %v, want none", o.transitions, o.lastTransition)
+	}
+	// Without a last deployed time the first observation is now.
+	if o := s.observe(other, "deployed", start); !o.since.Equal(start) {
+		t.Fatalf("since == %v, want %v", o.since, start)
+	}
+	// Same status keeps the time.
+	if o := s.observe(app, "deployed", start.Add(time.Minute)); !o.since.Equal(lastDeployed) {
+		t.Fatalf("since == %v, want %v", o.since, lastDeployed)
+	}
+
+	// Flapping between deployed and failed is counted per transition.
+	s.observe(app, "failed", start.Add(2*time.Minute))
+	s.observe(app, "deployed", start.Add(3*time.Minute))
+	o = s.observe(app, "failed", start.Add(4*time.Minute))
+
+	if !o.since.Equal(start.Add(4 * time.Minute)) {
+		t.Fatalf("since == %v, want %v", o.since, start.Add(4*time.Minute))
+	}
+	if !o.lastTransition.Equal(start.Add(4 * time.Minute)) {
+		t.Fatalf("lastTransition == %v, want %v", o.lastTransition, start.Add(4*time.Minute))
+	}
+	expectedTransitions := map[statusTransition]int{
+		{from: "deployed", to: "failed"}: 2,
+		{from: "failed", to: "deployed"}: 1,
+	}
+	if !reflect.DeepEqual(o.transitions, expectedTransitions) {
+		t.Fatalf("transitions == %v, want %v", o.transitions, expectedTransitions)
+	}
+
+	s.prune([]v1alpha1.App{app})
+	if len(s.statuses) != 1 {
+		t.Fatalf("len(statuses) == %d, want 1", len(s.statuses))
+	}
+}
@@ -0,0 +1,297 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TeamMappings are the mappings used to attribute apps to teams. They can be
+// swapped at runtime with App.SetTeamMappings.
+type TeamMappings struct {
+	// AppTeams maps app names to teams. It is used when the team annotation
+	// is missing in Chart.yaml.
+	AppTeams map[string]string `json:"appTeamMappings"`
+	// RetiredTeams maps retired teams to the teams which took over their
+	// apps.
+	RetiredTeams map[string]string `json:"retiredTeams"`
+	// KnownTeams are the valid teams alerts can be routed to. Apps
+	// attributed to any other team are reported. When empty every team is
+	// valid.
+	KnownTeams []string `json:"knownTeams"`
+}
+
+// Hash returns the SHA-256 of the mappings. It does not depend on the order
+// or formatting of the source the mappings were parsed from.
+func (m TeamMappings) Hash() string {
+	// Maps are marshalled with sorted keys so the result is stable.
+	b, err := json.Marshal(m)
+	if err != nil {
+		// Marshalling maps of strings cannot fail.
+		panic(err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate returns an invalidConfigError if the retired teams contain a
+// cycle, e.g. A retired into B and B retired into A. The apps of such teams
+// cannot be attributed to any active team. Teams retired into themselves are
+// not retired at all and are ignored.
+func (m TeamMappings) Validate() error {
+	var teams []string
+	for team := range m.RetiredTeams {
+		teams = append(teams, team)
+	}
+	slices.Sort(teams)
+
+	for _, team := range teams {
+		path := []string{team}
+		seen := map[string]int{team: 0}
+
+		for {
+			next := m.RetiredTeams[team]
+			if next == "" || next == team {
+				break
+			}
+
+			if i, ok := seen[next]; ok {
+				cycle := append(path[i:], next)
+				return microerror.Maskf(invalidConfigError, "retired teams contain the cycle %s", strings.Join(cycle, " -> "))
+			}
+
+			seen[next] = len(path)
+			path = append(path, next)
+			team = next
+		}
+	}
+
+	return nil
+}
+
+// IsKnownTeam returns true if the team is one of the known teams or no known
+// teams are configured. Known teams are normalized like the teams of apps so
+// they may have the team- prefix.
+func (m TeamMappings) IsKnownTeam(team string) bool {
+	if len(m.KnownTeams) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(m.KnownTeams, func(known string) bool {
+		return formatTeamName(known) == team
+	})
+}
+
+// RetiredTeam returns the team which took over the apps of the given team.
+// Mappings are resolved transitively, so if A was retired into B and B later
+// into C, apps of A are attributed to C. Teams which are not retired or
+// retired into themselves are returned as they are.
+func (m TeamMappings) RetiredTeam(team string) string {
+	// Validated mappings contain no cycles. The check only guards against
+	// looping forever on unvalidated ones.
+	seen := map[string]bool{}
+	for !seen[team] {
+		seen[team] = true
+
+		next := m.RetiredTeams[team]
+		if next == "" || next == team {
+			break
+		}
+
+		team = next
+	}
+
+	return team
+}
+
+// Sources of the teams an app may be attributed to.
+const (
+	TeamSourceACEOwners      = "ace-owners"
+	TeamSourceACETeam        = "ace-team"
+	TeamSourceAppAnnotation  = "app-annotation"
+	TeamSourceAppLabel       = "app-label"
+	TeamSourceAppTeamMapping = "app-team-mapping"
+	TeamSourceDefault        = "default"
+	TeamSourceRetiredTeams   = "retired-teams"
+)
+
+// teamSourcePrecedence orders the sources of candidate teams. The candidate
+// of the source coming first wins. The team label of the App CR overrides its
+// team annotation, which overrides the configured app team mappings and the
+// AppCatalogEntry CR. The default team is used if there is no other
+// candidate.
+var teamSourcePrecedence = []string{
+	TeamSourceAppLabel,
+	TeamSourceAppAnnotation,
+	TeamSourceAppTeamMapping,
+	TeamSourceACEOwners,
+	TeamSourceACETeam,
+	TeamSourceDefault,
+}
+
+// TeamCandidate is a team an app may be attributed to together with its
+// source.
+type TeamCandidate struct {
+	Source string `json:"source"`
+	Team   string `json:"team"`
+}
+
+// TeamDecision is the team an app is attributed to.
+type TeamDecision struct {
+	// Source is the source of the winning candidate.
+	Source string `json:"source"`
+	Team   string `json:"team"`
+	// RetiredTeam is the team of the winning candidate if it was retired
+	// and replaced with Team.
+	RetiredTeam string `json:"retiredTeam,omitempty"`
+	// UnknownTeam is the team the app would be attributed to if it is not
+	// one of the known teams.
+	UnknownTeam string `json:"unknownTeam,omitempty"`
+}
+
+// TeamExplanation lists every candidate team of an App CR together with the
+// team it is attributed to. It explains which precedence rule won.
+type TeamExplanation struct {
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace"`
+	Candidates []TeamCandidate `json:"candidates"`
+	Decision   TeamDecision    `json:"decision"`
+}
+
+// decideTeam picks the candidate with the highest precedence. Teams of the
+// App CR and the AppCatalogEntry CR are replaced if they were retired. The
+// configured app team mappings and the default team are used as they are.
+func decideTeam(candidates []TeamCandidate, mappings *TeamMappings, defaultTeam string) TeamDecision {
+	winner := TeamCandidate{Source: TeamSourceDefault, Team: defaultTeam}
+	for _, source := range teamSourcePrecedence {
+		i := slices.IndexFunc(candidates, func(c TeamCandidate) bool {
+			return c.Source == source && c.Team != ""
+		})
+		if i >= 0 {
+			winner = candidates[i]
+			break
+		}
+	}
+
+	d := TeamDecision{
+		Source: winner.Source,
+		Team:   winner.Team,
+	}
+
+	if winner.Source == TeamSourceAppTeamMapping || winner.Source == TeamSourceDefault {
+		return d
+	}
+
+	if team := mappings.RetiredTeam(winner.Team); team != winner.Team {
+		d.RetiredTeam = winner.Team
+		d.Team = team
+	}
+
+	return d
+}
+
+// checkKnownTeam reports the decided team if it is not one of the known teams.
+// The team is replaced with the default team if the fallback is enabled, so
+// alerts of the app are not lost.
+func (a *App) checkKnownTeam(d TeamDecision, mappings *TeamMappings) TeamDecision {
+	if mappings.IsKnownTeam(d.Team) {
+		return d
+	}
+
+	d.UnknownTeam = d.Team
+
+	if a.unknownTeamFallback {
+		d.Source = TeamSourceDefault
+		d.Team = a.defaultTeam
+	}
+
+	return d
+}
+
+var teamMappingsInfoDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(selfNamespace, "team_mappings", "info"),
+	"Hash of the active team mappings.",
+	[]string{
+		labelHash,
+	},
+	nil,
+)
+
+// SetTeamMappings atomically replaces the team mappings used by the following
+// collections. The mappings must be valid, see TeamMappings.Validate. Their
+// hash is exposed on collection, so it always matches the active mappings.
+func (a *App) SetTeamMappings(m TeamMappings) {
+	if m.AppTeams == nil {
+		m.AppTeams = map[string]string{}
+	}
+	if m.RetiredTeams == nil {
+		m.RetiredTeams = map[string]string{}
+	}
+
+	a.teamMappings.Store(&m)
+}
+
+// ExplainTeam returns the candidate teams of the given App CR and the team it
+// is attributed to using the active team mappings. It returns a notFoundError
+// if the App CR does not exist or is not collected.
+func (a *App) ExplainTeam(ctx context.Context, namespace, name string) (TeamExplanation, error) {
+	if !isWatchedNamespace(a.namespaces, namespace) {
+		return TeamExplanation{}, microerror.Maskf(notFoundError, "namespace %#q is not watched", namespace)
+	}
+
+	app := &v1alpha1.App{}
+	err := a.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, app)
+	if apierrors.IsNotFound(err) {
+		return TeamExplanation{}, microerror.Maskf(notFoundError, "app %#q in namespace %#q", name, namespace)
+	} else if err != nil {
+		return TeamExplanation{}, microerror.Mask(err)
+	}
+
+	if a.labelSelector != nil && !a.labelSelector.Matches(labels.Set(app.Labels)) {
+		return TeamExplanation{}, microerror.Maskf(notFoundError, "app %#q in namespace %#q does not match the label selector", name, namespace)
+	}
+
+	mappings := a.teamMappings.Load()
+
+	aceCandidates, err := a.getTeamCandidates(ctx, *app, mappings)
+	if err != nil {
+		return TeamExplanation{}, microerror.Mask(err)
+	}
+
+	return a.explainTeam(*app, aceCandidates, mappings), nil
+}
+
+// explainTeam decides the team of the App CR. It is used by collections and
+// ExplainTeam alike so the explained team always matches the exported one.
+// The candidates of the AppCatalogEntry CR are passed in as collections look
+// them up once per entry.
+func (a *App) explainTeam(app v1alpha1.App, aceCandidates []TeamCandidate, mappings *TeamMappings) TeamExplanation {
+	candidates := append(slices.Clone(aceCandidates), getAppTeamCandidates(app)...)
+	candidates = append(candidates, TeamCandidate{Source: TeamSourceDefault, Team: a.defaultTeam})
+
+	d := a.checkKnownTeam(decideTeam(candidates, mappings, a.defaultTeam), mappings)
+	if d.RetiredTeam != "" {
+		candidates = append(candidates, TeamCandidate{Source: TeamSourceRetiredTeams, Team: d.Team})
+	}
+
+	e := TeamExplanation{
+		Name:       app.Name,
+		Namespace:  app.Namespace,
+		Candidates: candidates,
+		Decision:   d,
+	}
+
+	return e
+}
@@ -0,0 +1,366 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	prometheustest "github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_SetTeamMappings(t *testing.T) {
+	var err error
+
+	app := newFakeApp(t, AppConfig{
+		AppTeamMappings: map[string]string{"hello-world-app": "batman"},
+	})
+
+	apps := []v1alpha1.App{
+		*newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", nil, nil),
+	}
+
+	teamMappings, err := app.getTeamMappings(context.TODO(), apps, app.teamMappings.Load())
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if team := decideTeam(teamMappings["giantswarm-hello-world-app-0.3.0"], app.teamMappings.Load(), "").Team; team != "batman" {
+		t.Fatalf("team == %#q, want %#q", team, "batman")
+	}
+
+	m := TeamMappings{
+		AppTeams:     map[string]string{"hello-world-app": "atlas"},
+		RetiredTeams: map[string]string{},
+	}
+	app.SetTeamMappings(m)
+
+	teamMappings, err = app.getTeamMappings(context.TODO(), apps, app.teamMappings.Load())
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if team := decideTeam(teamMappings["giantswarm-hello-world-app-0.3.0"], app.teamMappings.Load(), "").Team; team != "atlas" {
+		t.Fatalf("team == %#q, want %#q", team, "atlas")
+	}
+
+	expected := `
+# HELP app_exporter_team_mappings_info Hash of the active team mappings.
+# TYPE app_exporter_team_mappings_info gauge
+app_exporter_team_mappings_info{hash="` + m.Hash() + `"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(selfNamespace, "team_mappings", "info"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+}
+
+func Test_TeamMappingsHash(t *testing.T) {
+	a := TeamMappings{
+		AppTeams:     map[string]string{"a": "atlas", "b": "honeybadger"},
+		RetiredTeams: map[string]string{},
+	}
+	b := TeamMappings{
+		AppTeams:     map[string]string{"b": "honeybadger", "a": "atlas"},
+		RetiredTeams: map[string]string{},
+	}
+	c := TeamMappings{
+		AppTeams:     map[string]string{"a": "atlas", "b": "batman"},
+		RetiredTeams: map[string]string{},
+	}
+
+	if a.Hash() != b.Hash() {
+		t.Fatalf("hash of equal mappings differs")
+	}
+	if a.Hash() == c.Hash() {
+		t.Fatalf("hash of different mappings is equal")
+	}
+}
+
+func Test_TeamMappingsRetiredTeam(t *testing.T) {
+	m := TeamMappings{
+		RetiredTeams: map[string]string{
+			"batman":  "atlas",
+			"atlas":   "honeybadger",
+			"cabbage": "rocket",
+			"rocket":  "rocket",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		team     string
+		expected string
+	}{
+		{
+			name:     "case 0: retired transitively",
+			team:     "batman",
+			expected: "honeybadger",
+		},
+		{
+			name:     "case 1: retired once",
+			team:     "cabbage",
+			expected: "rocket",
+		},
+		{
+			name:     "case 2: not retired",
+			team:     "honeybadger",
+			expected: "honeybadger",
+		},
+		{
+			name:     "case 3: retired into itself",
+			team:     "rocket",
+			expected: "rocket",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			team := m.RetiredTeam(tc.team)
+			if team != tc.expected {
+				t.Fatalf("team == %#q, want %#q", team, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_TeamMappingsValidate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		retiredTeams map[string]string
+		valid        bool
+	}{
+		{
+			name: "case 0: chain",
+			retiredTeams: map[string]string{
+				"batman": "atlas",
+				"atlas":  "honeybadger",
+			},
+			valid: true,
+		},
+		{
+			name: "case 1: cycle",
+			retiredTeams: map[string]string{
+				"batman":      "atlas",
+				"atlas":       "honeybadger",
+				"honeybadger": "batman",
+			},
+			valid: false,
+		},
+		{
+			name: "case 2: self reference",
+			retiredTeams: map[string]string{
+				"batman": "batman",
+				"atlas":  "batman",
+			},
+			valid: true,
+		},
+		{
+			name: "case 3: chain into a cycle",
+			retiredTeams: map[string]string{
+				"cabbage": "atlas",
+				"atlas":   "honeybadger",
+				"rocket":  "honeybadger",
+				"phoenix": "rocket",
+				// honeybadger -> rocket -> honeybadger
+				"honeybadger": "rocket",
+			},
+			valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := TeamMappings{RetiredTeams: tc.retiredTeams}.Validate()
+			if tc.valid && err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if !tc.valid && !IsInvalidConfig(err) {
+				t.Fatalf("error == %#v, want invalidConfigError", err)
+			}
+		})
+	}
+}
+
+func Test_collectAppStatusRetiredTeams(t *testing.T) {
+	var err error
+
+	gsObj := []runtime.Object{
+		newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", map[string]string{
+			annotation.AppOperatorPaused: "true",
+			annotation.AppTeam:           "team-batman",
+		}, nil),
+		newApp("example", "customer", "default", "1.0.0", "", "", map[string]string{
+			annotation.AppOperatorPaused: "true",
+		}, map[string]string{
+			annotation.AppTeam: "atlas",
+		}),
+	}
+
+	appConfig := AppConfig{
+		RetiredTeamsMapping: map[string]string{
+			"batman": "atlas",
+			"atlas":  "rocket",
+		},
+	}
+
+	app := newFakeApp(t, appConfig, gsObj...)
+
+	// Teams of the team annotation and label of App CRs are retired too.
+	expected := `
+# HELP app_operator_app_paused Apps which are not reconciled by app-operator because they are paused.
+# TYPE app_operator_app_paused gauge
+app_operator_app_paused{name="example",namespace="default",team="rocket"} 1
+app_operator_app_paused{name="hello-world-app",namespace="hello-world",team="rocket"} 1
+`
+
+	err = prometheustest.CollectAndCompare(
+		fakeCollector{app: app},
+		strings.NewReader(expected),
+		prometheus.BuildFQName(namespace, "app", "paused"),
+	)
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n %s", err)
+	}
+
+	// Cycles fail the validation of the config.
+	appConfig.RetiredTeamsMapping["rocket"] = "batman"
+
+	_, err = NewApp(AppConfig{
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{}),
+		Logger:    microloggertest.New(),
+
+		DefaultTeam:         "honeybadger",
+		Provider:            "aws",
+		RetiredTeamsMapping: appConfig.RetiredTeamsMapping,
+	})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("error == %#v, want invalidConfigError", err)
+	}
+}
+
+func Test_ExplainTeam(t *testing.T) {
+	var err error
+
+	gsObj := []runtime.Object{
+		newACE("hello-world-app", "giantswarm", "default", "0.3.0", "[{'team':'team-batman','catalog':'giantswarm'}]", "team-atlas", true),
+		newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", map[string]string{
+			annotation.AppTeam: "team-cabbage",
+		}, nil),
+	}
+
+	app := newFakeApp(t, AppConfig{
+		AppTeamMappings: map[string]string{"hello-world-app": "phoenix"},
+		RetiredTeamsMapping: map[string]string{
+			"cabbage": "rocket",
+		},
+	}, gsObj...)
+
+	e, err := app.ExplainTeam(context.TODO(), "hello-world", "hello-world-app")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	expected := TeamExplanation{
+		Name:      "hello-world-app",
+		Namespace: "hello-world",
+		Candidates: []TeamCandidate{
+			{Source: TeamSourceAppTeamMapping, Team: "phoenix"},
+			{Source: TeamSourceACEOwners, Team: "batman"},
+			{Source: TeamSourceACETeam, Team: "atlas"},
+			{Source: TeamSourceAppAnnotation, Team: "cabbage"},
+			{Source: TeamSourceDefault, Team: "honeybadger"},
+			{Source: TeamSourceRetiredTeams, Team: "rocket"},
+		},
+		Decision: TeamDecision{
+			Source:      TeamSourceAppAnnotation,
+			Team:        "rocket",
+			RetiredTeam: "cabbage",
+		},
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Fatalf("want matching explanation \n %s", cmp.Diff(e, expected))
+	}
+
+	_, err = app.ExplainTeam(context.TODO(), "hello-world", "missing")
+	if !IsNotFound(err) {
+		t.Fatalf("error == %#v, want notFoundError", err)
+	}
+}
+
+func Test_collectAppStatusUnknownTeam(t *testing.T) {
+	testCases := []struct {
+		name                string
+		unknownTeamFallback bool
+		expected            string
+	}{
+		{
+			name:                "case 0: unknown teams are reported",
+			unknownTeamFallback: false,
+			expected: `
+# HELP app_operator_app_paused Apps which are not reconciled by app-operator because they are paused.
+# TYPE app_operator_app_paused gauge
+app_operator_app_paused{name="example",namespace="default",team="atlas"} 1
+app_operator_app_paused{name="hello-world-app",namespace="hello-world",team="hydraa"} 1
+# HELP app_operator_app_unknown_team Apps attributed to a team which is not one of the known teams.
+# TYPE app_operator_app_unknown_team gauge
+app_operator_app_unknown_team{name="hello-world-app",namespace="hello-world",team="hydraa"} 1
+`,
+		},
+		{
+			name:                "case 1: unknown teams fall back to the default team",
+			unknownTeamFallback: true,
+			expected: `
+# HELP app_operator_app_paused Apps which are not reconciled by app-operator because they are paused.
+# TYPE app_operator_app_paused gauge
+app_operator_app_paused{name="example",namespace="default",team="atlas"} 1
+app_operator_app_paused{name="hello-world-app",namespace="hello-world",team="honeybadger"} 1
+# HELP app_operator_app_unknown_team Apps attributed to a team which is not one of the known teams.
+# TYPE app_operator_app_unknown_team gauge
+app_operator_app_unknown_team{name="hello-world-app",namespace="hello-world",team="hydraa"} 1
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+
+			gsObj := []runtime.Object{
+				newApp("hello-world-app", "giantswarm", "hello-world", "0.3.0", "", "", map[string]string{
+					annotation.AppOperatorPaused: "true",
+					annotation.AppTeam:           "team-hydraa",
+				}, nil),
+				newApp("example", "customer", "default", "1.0.0", "", "", map[string]string{
+					annotation.AppOperatorPaused: "true",
+					annotation.AppTeam:           "atlas",
+				}, nil),
+			}
+
+			app := newFakeApp(t, AppConfig{
+				KnownTeams:          []string{"team-atlas", "honeybadger", "hydra"},
+				UnknownTeamFallback: tc.unknownTeamFallback,
+			}, gsObj...)
+
+			err = prometheustest.CollectAndCompare(
+				fakeCollector{app: app},
+				strings.NewReader(tc.expected),
+				prometheus.BuildFQName(namespace, "app", "paused"),
+				prometheus.BuildFQName(namespace, "app", "unknown_team"),
+			)
+			if err != nil {
+				t.Errorf("unexpected collecting result:\n %s", err)
+			}
+		})
+	}
+}
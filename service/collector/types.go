@@ -1,5 +1,25 @@
 package collector
 
+import (
+	"github.com/Masterminds/semver/v3"
+)
+
+// catalogAppKey identifies an app in a catalog. Catalogs are namespaced so
+// catalogs of different organizations may have the same name.
+type catalogAppKey struct {
+	namespace string
+	catalog   string
+	app       string
+}
+
+// appVersions holds the versions of an app in a catalog.
+type appVersions struct {
+	// latest is the version of the AppCatalogEntry CR labelled latest=true.
+	latest string
+	// released are all versions without a pre-release.
+	released []*semver.Version
+}
+
 type owner struct {
 	Catalog  string
 	Provider string
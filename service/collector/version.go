@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	upgradeKindMajor      = "major"
+	upgradeKindMinor      = "minor"
+	upgradeKindPatch      = "patch"
+	upgradeKindPrerelease = "prerelease"
+	upgradeKindUnknown    = "unknown"
+)
+
+// upgradeKind returns the kind of upgrade from the current to the latest
+// version or an empty string if the latest version is not newer.
+//
+// Versions are compared according to semantic versioning. Build metadata is
+// ignored, so 1.0.0+build.2 is no upgrade for 1.0.0+build.1. A pre-release is
+// older than its release, so 1.0.0 is a prerelease upgrade for 1.0.0-rc.1,
+// whereas an app pinned to 1.1.0-rc.1 has no upgrade to 1.0.0. When either
+// version is not valid semver we fall back to comparing the strings and the
+// kind is unknown.
+func upgradeKind(current, latest string) string {
+	if latest == "" || current == latest {
+		return ""
+	}
+
+	c, err := semver.NewVersion(current)
+	if err != nil {
+		return upgradeKindUnknown
+	}
+	l, err := semver.NewVersion(latest)
+	if err != nil {
+		return upgradeKindUnknown
+	}
+
+	if !l.GreaterThan(c) {
+		return ""
+	}
+
+	switch {
+	case l.Major() != c.Major():
+		return upgradeKindMajor
+	case l.Minor() != c.Minor():
+		return upgradeKindMinor
+	case l.Patch() != c.Patch():
+		return upgradeKindPatch
+	default:
+		return upgradeKindPrerelease
+	}
+}
+
+// versionsBehind counts the released versions newer than the current version
+// up to and including the latest version. They are counted by the kind of
+// upgrade they would be for the current version, e.g. for 1.2.3 the versions
+// 1.2.4, 1.3.0 and 2.0.0 count as one patch, minor and major version behind.
+func versionsBehind(current, latest string, released []*semver.Version) (map[string]int, error) {
+	c, err := semver.NewVersion(current)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	l, err := semver.NewVersion(latest)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	behind := map[string]int{
+		upgradeKindMajor: 0,
+		upgradeKindMinor: 0,
+		upgradeKindPatch: 0,
+	}
+
+	for _, v := range released {
+		if !v.GreaterThan(c) || v.GreaterThan(l) {
+			continue
+		}
+
+		switch {
+		case v.Major() != c.Major():
+			behind[upgradeKindMajor]++
+		case v.Minor() != c.Minor():
+			behind[upgradeKindMinor]++
+		default:
+			behind[upgradeKindPatch]++
+		}
+	}
+
+	return behind, nil
+}
@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func Test_upgradeKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		latest   string
+		expected string
+	}{
+		{
+			name:     "no latest version",
+			current:  "1.0.0",
+			expected: "",
+		},
+		{
+			name:     "same version",
+			current:  "1.0.0",
+			latest:   "1.0.0",
+			expected: "",
+		},
+		{
+			name:     "major upgrade",
+			current:  "1.2.3",
+			latest:   "2.0.0",
+			expected: upgradeKindMajor,
+		},
+		{
+			name:     "minor upgrade",
+			current:  "1.2.3",
+			latest:   "1.3.0",
+			expected: upgradeKindMinor,
+		},
+		{
+			name:     "patch upgrade",
+			current:  "1.2.3",
+			latest:   "1.2.4",
+			expected: upgradeKindPatch,
+		},
+		{
+			name:     "release of pinned pre-release",
+			current:  "1.2.3-rc.1",
+			latest:   "1.2.3",
+			expected: upgradeKindPrerelease,
+		},
+		{
+			name:     "pinned to newer pre-release",
+			current:  "1.3.0-rc.1",
+			latest:   "1.2.3",
+			expected: "",
+		},
+		{
+			name:     "pinned to newer version",
+			current:  "2.0.0",
+			latest:   "1.2.3",
+			expected: "",
+		},
+		{
+			name:     "build metadata only",
+			current:  "1.2.3+build.1",
+			latest:   "1.2.3+build.2",
+			expected: "",
+		},
+		{
+			name:     "invalid version",
+			current:  "1.2.3-foo_bar",
+			latest:   "1.2.4",
+			expected: upgradeKindUnknown,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			got := upgradeKind(tc.current, tc.latest)
+			if got != tc.expected {
+				t.Errorf("upgradeKind(%#q, %#q) = %#q, want %#q", tc.current, tc.latest, got, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_versionsBehind(t *testing.T) {
+	var released []*semver.Version
+	for _, v := range []string{"1.2.3", "1.2.4", "1.2.5", "1.3.0", "1.4.0", "2.0.0", "2.1.0"} {
+		released = append(released, semver.MustParse(v))
+	}
+
+	tests := []struct {
+		name     string
+		current  string
+		latest   string
+		expected map[string]int
+	}{
+		{
+			name:    "up to date",
+			current: "2.1.0",
+			latest:  "2.1.0",
+			expected: map[string]int{
+				upgradeKindMajor: 0,
+				upgradeKindMinor: 0,
+				upgradeKindPatch: 0,
+			},
+		},
+		{
+			name:    "behind by all kinds",
+			current: "1.2.3",
+			latest:  "2.1.0",
+			expected: map[string]int{
+				upgradeKindMajor: 2,
+				upgradeKindMinor: 2,
+				upgradeKindPatch: 2,
+			},
+		},
+		{
+			name:    "versions above latest are not counted",
+			current: "1.2.3",
+			latest:  "1.3.0",
+			expected: map[string]int{
+				upgradeKindMajor: 0,
+				upgradeKindMinor: 1,
+				upgradeKindPatch: 2,
+			},
+		},
+		{
+			name:    "pre-release counts its release",
+			current: "1.4.0-rc.1",
+			latest:  "1.4.0",
+			expected: map[string]int{
+				upgradeKindMajor: 0,
+				upgradeKindMinor: 0,
+				upgradeKindPatch: 1,
+			},
+		},
+		{
+			name:    "pinned to newer version",
+			current: "3.0.0",
+			latest:  "2.1.0",
+			expected: map[string]int{
+				upgradeKindMajor: 0,
+				upgradeKindMinor: 0,
+				upgradeKindPatch: 0,
+			},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			got, err := versionsBehind(tc.current, tc.latest, released)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("versionsBehind(%#q, %#q) = %v, want %v", tc.current, tc.latest, got, tc.expected)
+			}
+		})
+	}
+}
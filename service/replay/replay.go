@@ -0,0 +1,147 @@
+// Package replay provides Kubernetes clients which serve objects loaded from
+// YAML manifests. It allows running the exporter against a snapshot of a
+// management cluster without having access to it.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// Config represents the configuration used to create replay clients.
+type Config struct {
+	Logger micrologger.Logger
+
+	// Dir is the directory the YAML manifests are loaded from. All files
+	// with a .yaml or .yml extension are loaded recursively. A file may hold
+	// multiple documents as well as v1 List objects.
+	Dir string
+}
+
+// NewClients creates clients with a fake controller-runtime client holding
+// all objects found in the configured directory.
+func NewClients(config Config) (k8sclient.Interface, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Dir == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Dir must not be empty", config)
+	}
+
+	// Same as the live clients we extend the global client-go scheme with the
+	// application CRs.
+	err := v1alpha1.AddToScheme(scheme.Scheme)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	objects, err := LoadObjects(config.Dir)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	config.Logger.Debugf(context.Background(), "loaded %d objects for replay from %#q", len(objects), config.Dir)
+
+	k8sClient := k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+		CtrlClient: clientfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithRuntimeObjects(objects...).
+			Build(),
+	})
+
+	return k8sClient, nil
+}
+
+// LoadObjects decodes all objects found in the YAML manifests of the given
+// directory.
+func LoadObjects(dir string) ([]runtime.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer()
+
+	var objects []runtime.Object
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return microerror.Maskf(invalidConfigError, "reading %#q failed: %s", path, err)
+			}
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+
+			decoded, err := decode(decoder, doc)
+			if err != nil {
+				return microerror.Maskf(invalidConfigError, "decoding %#q failed: %s", path, err)
+			}
+
+			objects = append(objects, decoded...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return objects, nil
+}
+
+func decode(decoder runtime.Decoder, doc []byte) ([]runtime.Object, error) {
+	obj, _, err := decoder.Decode(doc, nil, nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	list, ok := obj.(*corev1.List)
+	if !ok {
+		return []runtime.Object{obj}, nil
+	}
+
+	var objects []runtime.Object
+	for _, item := range list.Items {
+		decoded, err := decode(decoder, item.Raw)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		objects = append(objects, decoded...)
+	}
+
+	return objects, nil
+}
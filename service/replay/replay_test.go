@@ -0,0 +1,59 @@
+package replay
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_NewClients(t *testing.T) {
+	ctx := context.Background()
+
+	c := Config{
+		Logger: microloggertest.New(),
+
+		Dir: "testdata/snapshot",
+	}
+
+	k8sClient, err := NewClients(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	apps := &v1alpha1.AppList{}
+	err = k8sClient.CtrlClient().List(ctx, apps)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(apps.Items) != 2 {
+		t.Errorf("expected 2 apps, got %d", len(apps.Items))
+	}
+
+	catalogs := &v1alpha1.CatalogList{}
+	err = k8sClient.CtrlClient().List(ctx, catalogs)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(catalogs.Items) != 1 {
+		t.Errorf("expected 1 catalog, got %d", len(catalogs.Items))
+	}
+
+	ace := &v1alpha1.AppCatalogEntry{}
+	err = k8sClient.CtrlClient().Get(ctx, types.NamespacedName{Namespace: "default", Name: "giantswarm-hello-world-app-0.3.0"}, ace)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if ace.Spec.Version != "0.3.0" {
+		t.Errorf("expected version %#q, got %#q", "0.3.0", ace.Spec.Version)
+	}
+}
+
+func Test_NewClients_invalidConfig(t *testing.T) {
+	_, err := NewClients(Config{Logger: microloggertest.New()})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("error == %#v, want invalid config error", err)
+	}
+}
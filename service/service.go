@@ -13,12 +13,20 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	"github.com/giantswarm/app-exporter/flag"
 	"github.com/giantswarm/app-exporter/pkg/project"
+	"github.com/giantswarm/app-exporter/service/cache"
 	"github.com/giantswarm/app-exporter/service/collector"
+	"github.com/giantswarm/app-exporter/service/replay"
+	"github.com/giantswarm/app-exporter/service/teams"
 )
 
 // Config represents the configuration used to create a new service.
@@ -33,12 +41,14 @@ type Service struct {
 	Version *version.Service
 
 	bootOnce          sync.Once
+	cache             *cache.Cache
+	logger            micrologger.Logger
 	operatorCollector *collector.Set
+	teamsReloader     *teams.Reloader
 }
 
 // New creates a new configured service object.
 func New(config Config) (*Service, error) {
-	var serviceAddress string
 	// Settings.
 	if config.Flag == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.Flag must not be empty")
@@ -46,12 +56,6 @@ func New(config Config) (*Service, error) {
 	if config.Viper == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.Viper must not be empty")
 	}
-	if config.Flag.Service.Kubernetes.KubeConfig == "" {
-		serviceAddress = config.Viper.GetString(config.Flag.Service.Kubernetes.Address)
-	} else {
-		serviceAddress = ""
-	}
-
 	// Dependencies.
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "logger must not be empty")
@@ -59,41 +63,61 @@ func New(config Config) (*Service, error) {
 
 	var err error
 
-	var restConfig *rest.Config
-	{
-		c := k8srestconfig.Config{
+	replayDir := config.Viper.GetString(config.Flag.Service.Replay.Dir)
+
+	// In replay mode the collectors are served from YAML manifests instead of
+	// a live cluster.
+	var k8sClient k8sclient.Interface
+	if replayDir != "" {
+		c := replay.Config{
 			Logger: config.Logger,
 
-			Address:    serviceAddress,
-			InCluster:  config.Viper.GetBool(config.Flag.Service.Kubernetes.InCluster),
-			KubeConfig: config.Viper.GetString(config.Flag.Service.Kubernetes.KubeConfig),
-			TLS: k8srestconfig.ConfigTLS{
-				CAFile:  config.Viper.GetString(config.Flag.Service.Kubernetes.TLS.CAFile),
-				CrtFile: config.Viper.GetString(config.Flag.Service.Kubernetes.TLS.CrtFile),
-				KeyFile: config.Viper.GetString(config.Flag.Service.Kubernetes.TLS.KeyFile),
-			},
+			Dir: replayDir,
 		}
 
-		restConfig, err = k8srestconfig.New(c)
+		k8sClient, err = replay.NewClients(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	} else {
+		k8sClient, err = NewK8sClient(config)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
-	var k8sClient k8sclient.Interface
+	var appLabelSelector labels.Selector
 	{
-		c := k8sclient.ClientsConfig{
-			Logger: config.Logger,
-			SchemeBuilder: k8sclient.SchemeBuilder{
-				applicationv1alpha1.AddToScheme,
-			},
-			RestConfig: restConfig,
+		appLabelSelector, err = labels.Parse(config.Viper.GetString(config.Flag.Service.Kubernetes.Watch.LabelSelector))
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "parsing %#q failed: %s", config.Flag.Service.Kubernetes.Watch.LabelSelector, err)
 		}
+	}
 
-		k8sClient, err = k8sclient.NewClients(c)
+	watchNamespaces := config.Viper.GetStringSlice(config.Flag.Service.Kubernetes.Watch.Namespace)
+	configReferences := config.Viper.GetBool(config.Flag.Service.Collector.ConfigReferences.Enabled)
+	kubeConfigSecrets := config.Viper.GetBool(config.Flag.Service.Collector.KubeConfigSecrets.Enabled)
+
+	// The replayed objects are in memory already so they are read directly.
+	var k8sCache *cache.Cache
+	var reader client.Reader
+	if replayDir == "" {
+		c := cache.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			AppLabelSelector:  appLabelSelector,
+			ConfigReferences:  configReferences,
+			KubeConfigSecrets: kubeConfigSecrets,
+			Namespaces:        watchNamespaces,
+		}
+
+		k8sCache, err = cache.New(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
+
+		reader = k8sCache.Reader()
 	}
 
 	var appTeamMappings map[string]string
@@ -112,16 +136,43 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	var catalogLabelSelector labels.Selector
+	{
+		catalogLabelSelector, err = labels.Parse(config.Viper.GetString(config.Flag.Service.Collector.Catalogs.LabelSelector))
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "parsing %#q failed: %s", config.Flag.Service.Collector.Catalogs.LabelSelector, err)
+		}
+	}
+
+	// Events are not emitted in replay mode as there is no cluster to write
+	// them to.
+	var eventRecorder record.EventRecorder
+	if config.Viper.GetBool(config.Flag.Service.Collector.Events.Enabled) && replayDir == "" {
+		eventRecorder = newEventRecorder(k8sClient)
+	}
+
 	var operatorCollector *collector.Set
 	{
 		c := collector.SetConfig{
-			K8sClient: k8sClient,
-			Logger:    config.Logger,
+			EventRecorder: eventRecorder,
+			K8sClient:     k8sClient,
+			Logger:        config.Logger,
+			Reader:        reader,
 
-			AppTeamMappings:     appTeamMappings,
-			DefaultTeam:         config.Viper.GetString(config.Flag.Service.Collector.Apps.DefaultTeam),
-			Provider:            config.Viper.GetString(config.Flag.Service.Collector.Provider.Kind),
-			RetiredTeamsMapping: retiredTeamsMapping,
+			AppTeamMappings:      appTeamMappings,
+			CatalogAllowlist:     config.Viper.GetStringSlice(config.Flag.Service.Collector.Catalogs.Allowlist),
+			CatalogDenylist:      config.Viper.GetStringSlice(config.Flag.Service.Collector.Catalogs.Denylist),
+			CatalogLabelSelector: catalogLabelSelector,
+			ConfigReferences:     configReferences,
+			DefaultTeam:          config.Viper.GetString(config.Flag.Service.Collector.Apps.DefaultTeam),
+			KnownTeams:           config.Viper.GetStringSlice(config.Flag.Service.Collector.Apps.KnownTeams),
+			KubeConfigSecrets:    kubeConfigSecrets,
+			LabelSelector:        appLabelSelector,
+			Namespaces:           watchNamespaces,
+			Provider:             config.Viper.GetString(config.Flag.Service.Collector.Provider.Kind),
+			RetiredTeamsMapping:  retiredTeamsMapping,
+			Timeout:              config.Viper.GetDuration(config.Flag.Service.Collector.Timeout),
+			UnknownTeamFallback:  config.Viper.GetBool(config.Flag.Service.Collector.Apps.UnknownTeamFallback),
 		}
 
 		operatorCollector, err = collector.NewSet(c)
@@ -130,6 +181,33 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	// Team mappings from a file replace the ones from the flags and are
+	// reloaded whenever the file changes.
+	var teamsReloader *teams.Reloader
+	if path := config.Viper.GetString(config.Flag.Service.Collector.Apps.TeamMappingsFile); path != "" {
+		// The file replaces the flags also when it misses their keys, so
+		// setting both would silently drop the flags, e.g. the validation
+		// of the known teams.
+		set := config.Viper.GetString(config.Flag.Service.Collector.Apps.AppTeamMappings) != "" ||
+			config.Viper.GetString(config.Flag.Service.Collector.Apps.RetiredTeams) != "" ||
+			len(config.Viper.GetStringSlice(config.Flag.Service.Collector.Apps.KnownTeams)) > 0
+		if set {
+			return nil, microerror.Maskf(invalidConfigError, "%#q must not be set together with %#q, %#q or %#q", config.Flag.Service.Collector.Apps.TeamMappingsFile, config.Flag.Service.Collector.Apps.AppTeamMappings, config.Flag.Service.Collector.Apps.RetiredTeams, config.Flag.Service.Collector.Apps.KnownTeams)
+		}
+
+		c := teams.Config{
+			Logger: config.Logger,
+			Target: operatorCollector,
+
+			Path: path,
+		}
+
+		teamsReloader, err = teams.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var versionService *version.Service
 	{
 		c := version.Config{
@@ -150,7 +228,10 @@ func New(config Config) (*Service, error) {
 		Version: versionService,
 
 		bootOnce:          sync.Once{},
+		cache:             k8sCache,
+		logger:            config.Logger,
 		operatorCollector: operatorCollector,
+		teamsReloader:     teamsReloader,
 	}
 
 	return s, nil
@@ -158,10 +239,103 @@ func New(config Config) (*Service, error) {
 
 func (s *Service) Boot(ctx context.Context) {
 	s.bootOnce.Do(func() {
+		if s.teamsReloader != nil {
+			go s.teamsReloader.Boot(ctx)
+		}
+
+		// The collectors read from the cache so it must be synced before
+		// they are registered.
+		if s.cache != nil {
+			err := s.cache.Boot(ctx)
+			if ctx.Err() != nil {
+				return
+			} else if err != nil {
+				// Without a synced cache the collectors are not
+				// registered and /metrics is served empty, which
+				// silences every alert based on it. Crash instead so
+				// the failure is visible and the pod restarted.
+				panic(microerror.JSON(err))
+			}
+		}
+
 		go s.operatorCollector.Boot(ctx) // nolint:errcheck
 	})
 }
 
+// ExplainTeam returns the candidate teams of the given App CR and the team it
+// is attributed to.
+func (s *Service) ExplainTeam(ctx context.Context, namespace, name string) (collector.TeamExplanation, error) {
+	e, err := s.operatorCollector.ExplainTeam(ctx, namespace, name)
+	if err != nil {
+		return collector.TeamExplanation{}, microerror.Mask(err)
+	}
+
+	return e, nil
+}
+
+// NewK8sClient creates the clients to connect to the Kubernetes API configured
+// by the kubernetes flags.
+func NewK8sClient(config Config) (k8sclient.Interface, error) {
+	var err error
+
+	var serviceAddress string
+	if config.Flag.Service.Kubernetes.KubeConfig == "" {
+		serviceAddress = config.Viper.GetString(config.Flag.Service.Kubernetes.Address)
+	} else {
+		serviceAddress = ""
+	}
+
+	var restConfig *rest.Config
+	{
+		c := k8srestconfig.Config{
+			Logger: config.Logger,
+
+			Address:    serviceAddress,
+			InCluster:  config.Viper.GetBool(config.Flag.Service.Kubernetes.InCluster),
+			KubeConfig: config.Viper.GetString(config.Flag.Service.Kubernetes.KubeConfig),
+			TLS: k8srestconfig.ConfigTLS{
+				CAFile:  config.Viper.GetString(config.Flag.Service.Kubernetes.TLS.CAFile),
+				CrtFile: config.Viper.GetString(config.Flag.Service.Kubernetes.TLS.CrtFile),
+				KeyFile: config.Viper.GetString(config.Flag.Service.Kubernetes.TLS.KeyFile),
+			},
+		}
+
+		restConfig, err = k8srestconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var k8sClient k8sclient.Interface
+	{
+		c := k8sclient.ClientsConfig{
+			Logger: config.Logger,
+			SchemeBuilder: k8sclient.SchemeBuilder{
+				applicationv1alpha1.AddToScheme,
+			},
+			RestConfig: restConfig,
+		}
+
+		k8sClient, err = k8sclient.NewClients(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return k8sClient, nil
+}
+
+// newEventRecorder creates an event recorder writing events to the Kubernetes
+// API. Similar events are aggregated and rate limited by the recorder.
+func newEventRecorder(k8sClient k8sclient.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: k8sClient.K8sClient().CoreV1().Events(""),
+	})
+
+	return broadcaster.NewRecorder(k8sClient.Scheme(), corev1.EventSource{Component: project.Name()})
+}
+
 func newMapping(input string) (map[string]string, error) {
 	mapping := map[string]string{}
 	err := yaml.Unmarshal([]byte(input), &mapping)
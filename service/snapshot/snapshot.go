@@ -0,0 +1,338 @@
+// Package snapshot records all objects the exporter reads from a cluster into
+// YAML manifests which can be served again using the replay mode.
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-exporter/internal/key"
+	"github.com/giantswarm/app-exporter/pkg/project"
+	"github.com/giantswarm/app-exporter/service/cache"
+)
+
+const (
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// Config represents the configuration used to create a new snapshot.
+type Config struct {
+	K8sClient k8sclient.Interface
+	Logger    micrologger.Logger
+
+	// AppLabelSelector restricts the recorded App CRs. Optional.
+	AppLabelSelector labels.Selector
+	// Namespaces restricts the recorded objects to the given namespaces.
+	// When empty all namespaces are recorded.
+	Namespaces []string
+	// Output is the directory the manifests are written to. When it ends
+	// with .tar.gz or .tgz a gzipped tarball is written instead.
+	Output string
+}
+
+// Snapshot records App, Catalog and AppCatalogEntry CRs, app-operator
+// deployments and the names of the ConfigMaps and Secrets, including
+// kubeconfigs, referenced by the App CRs.
+type Snapshot struct {
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+
+	appLabelSelector labels.Selector
+	namespaces       []string
+	output           string
+}
+
+// New creates a new configured snapshot.
+func New(config Config) (*Snapshot, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Output == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Output must not be empty", config)
+	}
+
+	s := &Snapshot{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		appLabelSelector: config.AppLabelSelector,
+		namespaces:       config.Namespaces,
+		output:           config.Output,
+	}
+
+	return s, nil
+}
+
+// Record lists all objects read by the exporter, strips sensitive fields and
+// writes them to the configured output.
+func (s *Snapshot) Record(ctx context.Context) error {
+	files := map[string][]client.Object{}
+	recorded := map[key.ConfigReference]bool{}
+
+	for _, ns := range listNamespaces(s.namespaces) {
+		apps := &v1alpha1.AppList{}
+		err := s.k8sClient.CtrlClient().List(ctx, apps, &client.ListOptions{Namespace: ns, LabelSelector: s.appLabelSelector})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for i := range apps.Items {
+			files["apps.yaml"] = append(files["apps.yaml"], &apps.Items[i])
+		}
+
+		for _, app := range apps.Items {
+			refs := key.ConfigReferences(app)
+			if ref, ok := key.KubeConfigReference(app); ok && ref.Name != "" && ref.Namespace != "" {
+				refs = append(refs, ref)
+			}
+
+			for _, ref := range refs {
+				m, err := s.recordReference(ctx, ref, recorded)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				if m == nil {
+					continue
+				}
+
+				name := strings.ToLower(ref.Kind) + "s.yaml"
+				files[name] = append(files[name], m)
+			}
+		}
+
+		catalogs := &v1alpha1.CatalogList{}
+		err = s.k8sClient.CtrlClient().List(ctx, catalogs, client.InNamespace(ns))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for i := range catalogs.Items {
+			files["catalogs.yaml"] = append(files["catalogs.yaml"], &catalogs.Items[i])
+		}
+
+		aces := &v1alpha1.AppCatalogEntryList{}
+		err = s.k8sClient.CtrlClient().List(ctx, aces, client.InNamespace(ns))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for i := range aces.Items {
+			files["appcatalogentries.yaml"] = append(files["appcatalogentries.yaml"], &aces.Items[i])
+		}
+
+		deployments := &appsv1.DeploymentList{}
+		err = s.k8sClient.CtrlClient().List(ctx, deployments, client.InNamespace(ns), client.MatchingLabels{
+			label.App: project.OperatorName(),
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for i := range deployments.Items {
+			files["deployments.yaml"] = append(files["deployments.yaml"], &deployments.Items[i])
+		}
+	}
+
+	manifests := map[string][]byte{}
+	for name, objects := range files {
+		var b bytes.Buffer
+
+		for _, obj := range objects {
+			err := s.sanitize(obj)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			out, err := yaml.Marshal(obj)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			b.WriteString("---\n")
+			b.Write(out)
+		}
+
+		manifests[name] = b.Bytes()
+
+		s.logger.Debugf(ctx, "recorded %d objects into %#q", len(objects), name)
+	}
+
+	var err error
+	if strings.HasSuffix(s.output, ".tar.gz") || strings.HasSuffix(s.output, ".tgz") {
+		err = writeTarball(s.output, manifests)
+	} else {
+		err = writeDir(s.output, manifests)
+	}
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	s.logger.Debugf(ctx, "wrote snapshot to %#q", s.output)
+
+	return nil
+}
+
+// recordReference returns the metadata of the referenced ConfigMap or Secret
+// with only its name and namespace. It returns nil if the reference was
+// recorded before, points to a namespace which is not recorded or does not
+// exist. The data is never read.
+func (s *Snapshot) recordReference(ctx context.Context, ref key.ConfigReference, recorded map[key.ConfigReference]bool) (client.Object, error) {
+	if recorded[ref] {
+		return nil, nil
+	}
+	if len(s.namespaces) > 0 && !slices.Contains(s.namespaces, ref.Namespace) {
+		return nil, nil
+	}
+
+	recorded[ref] = true
+
+	m := cache.NewMetadata(ref.Kind)
+	err := s.k8sClient.CtrlClient().Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, m)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	m = cache.NewMetadata(ref.Kind)
+	m.SetName(ref.Name)
+	m.SetNamespace(ref.Namespace)
+
+	return m, nil
+}
+
+// sanitize sets the type meta required to decode the object again and strips
+// fields which are irrelevant for the exporter or may hold sensitive data.
+func (s *Snapshot) sanitize(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, s.k8sClient.Scheme())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	obj.SetManagedFields(nil)
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, lastAppliedConfigAnnotation)
+	obj.SetAnnotations(annotations)
+
+	switch o := obj.(type) {
+	case *v1alpha1.Catalog:
+		// Repository URLs may carry credentials.
+		o.Spec.Storage.URL = stripUserInfo(o.Spec.Storage.URL)
+		for i := range o.Spec.Repositories {
+			o.Spec.Repositories[i].URL = stripUserInfo(o.Spec.Repositories[i].URL)
+		}
+	case *appsv1.Deployment:
+		// Only labels and the status are read. The pod template may hold
+		// environment variables with credentials.
+		o.Spec.Template = corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: o.Spec.Template.Labels,
+			},
+		}
+	}
+
+	return nil
+}
+
+func listNamespaces(namespaces []string) []string {
+	if len(namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+
+	return namespaces
+}
+
+func stripUserInfo(input string) string {
+	u, err := url.Parse(input)
+	if err != nil || u.User == nil {
+		return input
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+func writeDir(dir string, manifests map[string][]byte) error {
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for name, b := range manifests {
+		err = os.WriteFile(filepath.Join(dir, name), b, 0600)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func writeTarball(path string, manifests map[string][]byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	for _, name := range slices.Sorted(maps.Keys(manifests)) {
+		b := manifests[name]
+		h := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(b)),
+			ModTime: time.Now(),
+		}
+
+		err = tw.WriteHeader(h)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		_, err = tw.Write(b)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = gw.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return microerror.Mask(f.Close())
+}
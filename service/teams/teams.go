@@ -0,0 +1,179 @@
+// Package teams reloads the team mappings of the app collector from a file
+// whenever it changes, e.g. when the ConfigMap it is mounted from is updated.
+// This way team attributions change without restarting the exporter.
+package teams
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-exporter/service/collector"
+)
+
+// DefaultInterval is how often the file is checked for changes. The kubelet
+// only syncs mounted ConfigMaps every minute or so, so checking more often
+// does not pick up changes faster.
+const DefaultInterval = 30 * time.Second
+
+const (
+	resultFailure = "failure"
+	resultSuccess = "success"
+)
+
+var reloadsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "app_exporter",
+		Subsystem: "team_mappings",
+		Name:      "reloads_total",
+		Help:      "Number of reloads of the team mappings file by result.",
+	},
+	[]string{
+		"result",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(reloadsTotal)
+}
+
+// Target receives the reloaded team mappings.
+type Target interface {
+	SetTeamMappings(m collector.TeamMappings)
+}
+
+// Config represents the configuration used to create a new reloader.
+type Config struct {
+	Logger micrologger.Logger
+	Target Target
+
+	// Interval is how often the file is checked for changes. Defaults to
+	// DefaultInterval.
+	Interval time.Duration
+	// Path of the YAML file holding the team mappings.
+	Path string
+}
+
+// Reloader checks the team mappings file for changes and passes the mappings
+// to its target whenever the content changed and is valid. Invalid content is
+// logged and counted once and the mappings loaded last stay active.
+type Reloader struct {
+	logger micrologger.Logger
+	target Target
+
+	// content is the content of the file read last, valid or not.
+	content  []byte
+	interval time.Duration
+	path     string
+}
+
+// New creates a new configured reloader. The file must exist and be valid as
+// its mappings are loaded right away.
+func New(config Config) (*Reloader, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Target == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Target must not be empty", config)
+	}
+
+	if config.Path == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Path must not be empty", config)
+	}
+
+	interval := config.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	r := &Reloader{
+		logger: config.Logger,
+		target: config.Target,
+
+		interval: interval,
+		path:     config.Path,
+	}
+
+	_, err := r.reload()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return r, nil
+}
+
+// Boot checks the file for changes until the given context is cancelled.
+func (r *Reloader) Boot(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				r.logger.Errorf(ctx, err, "failed to reload team mappings from %#q", r.path)
+			} else if reloaded {
+				r.logger.Debugf(ctx, "reloaded team mappings from %#q", r.path)
+			}
+		}
+	}
+}
+
+// reload reads the file and passes its mappings to the target if the content
+// changed. It returns whether the mappings were replaced.
+func (r *Reloader) reload() (bool, error) {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		reloadsTotal.WithLabelValues(resultFailure).Inc()
+		return false, microerror.Mask(err)
+	}
+
+	if r.content != nil && bytes.Equal(content, r.content) {
+		return false, nil
+	}
+	r.content = content
+
+	m, err := Parse(content)
+	if err != nil {
+		reloadsTotal.WithLabelValues(resultFailure).Inc()
+		return false, microerror.Mask(err)
+	}
+
+	r.target.SetTeamMappings(m)
+	reloadsTotal.WithLabelValues(resultSuccess).Inc()
+
+	return true, nil
+}
+
+// Parse parses and validates the team mappings from YAML like the following.
+//
+//	appTeamMappings:
+//	  hello-world-app: honeybadger
+//	retiredTeams:
+//	  batman: honeybadger
+//	knownTeams:
+//	- atlas
+//	- honeybadger
+func Parse(content []byte) (collector.TeamMappings, error) {
+	var m collector.TeamMappings
+	err := yaml.UnmarshalStrict(content, &m)
+	if err != nil {
+		return collector.TeamMappings{}, microerror.Maskf(invalidConfigError, "parsing team mappings failed: %s", err)
+	}
+
+	err = m.Validate()
+	if err != nil {
+		return collector.TeamMappings{}, microerror.Mask(err)
+	}
+
+	return m, nil
+}
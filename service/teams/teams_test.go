@@ -0,0 +1,111 @@
+package teams
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	prometheustest "github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/giantswarm/app-exporter/service/collector"
+)
+
+type fakeTarget struct {
+	mappings []collector.TeamMappings
+}
+
+func (t *fakeTarget) SetTeamMappings(m collector.TeamMappings) {
+	t.mappings = append(t.mappings, m)
+}
+
+func Test_Reloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "teams.yml")
+	write := func(content string) {
+		err := os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("appTeamMappings:\n  hello-world-app: honeybadger\n")
+
+	target := &fakeTarget{}
+	failures := prometheustest.ToFloat64(reloadsTotal.WithLabelValues(resultFailure))
+	successes := prometheustest.ToFloat64(reloadsTotal.WithLabelValues(resultSuccess))
+
+	r, err := New(Config{
+		Logger: microloggertest.New(),
+		Target: target,
+
+		Path: path,
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	// Unchanged content is not reloaded.
+	reloaded, err := r.reload()
+	if err != nil || reloaded {
+		t.Fatalf("reloaded == %t, error == %#v, want false and nil", reloaded, err)
+	}
+
+	// Invalid content keeps the active mappings.
+	write("appTeamMappings: [honeybadger]\n")
+	reloaded, err = r.reload()
+	if !IsInvalidConfig(err) || reloaded {
+		t.Fatalf("reloaded == %t, error == %#v, want false and invalidConfigError", reloaded, err)
+	}
+	// It is counted once only.
+	_, _ = r.reload()
+
+	// Cycles in the retired teams are invalid.
+	write("retiredTeams:\n  batman: honeybadger\n  honeybadger: batman\n")
+	reloaded, err = r.reload()
+	if !collector.IsInvalidConfig(err) || reloaded {
+		t.Fatalf("reloaded == %t, error == %#v, want false and invalidConfigError", reloaded, err)
+	}
+
+	write("appTeamMappings:\n  hello-world-app: atlas\nretiredTeams:\n  batman: honeybadger\nknownTeams:\n- atlas\n- honeybadger\n")
+	reloaded, err = r.reload()
+	if err != nil || !reloaded {
+		t.Fatalf("reloaded == %t, error == %#v, want true and nil", reloaded, err)
+	}
+
+	expected := []collector.TeamMappings{
+		{
+			AppTeams: map[string]string{"hello-world-app": "honeybadger"},
+		},
+		{
+			AppTeams:     map[string]string{"hello-world-app": "atlas"},
+			RetiredTeams: map[string]string{"batman": "honeybadger"},
+			KnownTeams:   []string{"atlas", "honeybadger"},
+		},
+	}
+	if !reflect.DeepEqual(target.mappings, expected) {
+		t.Fatalf("mappings == %v, want %v", target.mappings, expected)
+	}
+
+	if got := prometheustest.ToFloat64(reloadsTotal.WithLabelValues(resultSuccess)) - successes; got != 2 {
+		t.Fatalf("successful reloads == %v, want 2", got)
+	}
+	if got := prometheustest.ToFloat64(reloadsTotal.WithLabelValues(resultFailure)) - failures; got != 2 {
+		t.Fatalf("failed reloads == %v, want 2", got)
+	}
+}
+
+func Test_New(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "teams.yml")
+
+	// The file must exist at startup.
+	_, err := New(Config{
+		Logger: microloggertest.New(),
+		Target: &fakeTarget{},
+
+		Path: path,
+	})
+	if err == nil {
+		t.Fatalf("error == nil, want error")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	microflag "github.com/giantswarm/microkit/flag"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/giantswarm/app-exporter/service"
+	"github.com/giantswarm/app-exporter/service/snapshot"
+)
+
+const (
+	snapshotOutputFlag = "output"
+)
+
+// newSnapshotCommand creates the command recording all objects the exporter
+// reads into YAML manifests which can be served using the replay mode.
+func newSnapshotCommand(ctx context.Context, logger micrologger.Logger) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Record the objects read by the exporter for the replay mode.",
+		Long: "Record App, Catalog and AppCatalogEntry CRs as well as app-operator deployments into YAML manifests. " +
+			"Sensitive fields are stripped. Pass the directory to --service.replay.dir of the daemon command to serve " +
+			"metrics from it. Tarballs need to be extracted first.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.New()
+			microflag.Parse(v, cmd.Flags())
+
+			err := runSnapshot(ctx, logger, v)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			return nil
+		},
+	}
+
+	c.Flags().String(snapshotOutputFlag, "snapshot", "Directory to write the manifests to. When it ends with .tar.gz or .tgz a gzipped tarball is written instead.")
+	addKubernetesFlags(c.Flags())
+
+	return c
+}
+
+func runSnapshot(ctx context.Context, logger micrologger.Logger, v *viper.Viper) error {
+	var err error
+
+	var k8sClient k8sclient.Interface
+	{
+		c := service.Config{
+			Logger: logger,
+
+			Flag:  f,
+			Viper: v,
+		}
+
+		k8sClient, err = service.NewK8sClient(c)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	appLabelSelector, err := labels.Parse(v.GetString(f.Service.Kubernetes.Watch.LabelSelector))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var s *snapshot.Snapshot
+	{
+		c := snapshot.Config{
+			K8sClient: k8sClient,
+			Logger:    logger,
+
+			AppLabelSelector: appLabelSelector,
+			Namespaces:       v.GetStringSlice(f.Service.Kubernetes.Watch.Namespace),
+			Output:           v.GetString(snapshotOutputFlag),
+		}
+
+		s, err = snapshot.New(c)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	err = s.Record(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}